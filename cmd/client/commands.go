@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient"
+)
+
+type anchorOutput struct {
+	Assertion   common.Hash `json:"assertion"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	Provers     []string    `json:"provers"`
+}
+
+type survivorOutput struct {
+	Prover      string      `json:"prover"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+}
+
+type tournamentOutput struct {
+	Anchor    anchorOutput     `json:"anchor"`
+	At        uint64           `json:"at,omitempty"`
+	Survivors []survivorOutput `json:"survivors"`
+}
+
+type oracleOutput struct {
+	Oracle   string `json:"oracle"`
+	Prover   string `json:"prover"`
+	Block    uint64 `json:"block"`
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+type assertionOutput struct {
+	Hash                common.Hash `json:"hash"`
+	ParentAssertionHash common.Hash `json:"parentAssertionHash"`
+	Status              uint8       `json:"status"`
+	CreatedAtBlock      uint64      `json:"createdAtBlock"`
+	ConfirmedAtBlock    uint64      `json:"confirmedAtBlock"`
+	BlockHash           common.Hash `json:"blockHash"`
+	SendRoot            common.Hash `json:"sendRoot"`
+	Batch               uint64      `json:"batch"`
+	PosInBatch          uint64      `json:"posInBatch"`
+	AfterInboxBatchAcc  common.Hash `json:"afterInboxBatchAcc"`
+	InboxMaxCount       string      `json:"inboxMaxCount"`
+	WasmModuleRoot      common.Hash `json:"wasmModuleRoot"`
+	ConfirmPeriodBlocks uint64      `json:"confirmPeriodBlocks"`
+	Trust               string      `json:"trust"`
+}
+
+type pendingOutput struct {
+	Confirmed assertionOutput                 `json:"confirmed"`
+	Pending   []*lightclient.PendingAssertion `json:"pending"`
+}
+
+type measureOutput struct {
+	Kind    string                          `json:"kind"`
+	Summary *lightclient.MeasurementSummary `json:"summary"`
+}
+
+func writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func newAnchorOutput(anchor *lightclient.Anchor) anchorOutput {
+	out := anchorOutput{
+		Assertion:   anchor.Assertion.Hash,
+		BlockNumber: anchor.Header.Number.Uint64(),
+		BlockHash:   anchor.Header.Hash(),
+		Provers:     make([]string, 0, len(anchor.Provers)),
+	}
+	for _, prover := range anchor.Provers {
+		out.Provers = append(out.Provers, prover.URL())
+	}
+	return out
+}
+
+func newTournamentOutput(anchor *lightclient.Anchor, result *lightclient.TournamentResult, at uint64) tournamentOutput {
+	out := tournamentOutput{
+		Anchor:    newAnchorOutput(anchor),
+		At:        at,
+		Survivors: make([]survivorOutput, 0, len(result.Survivors)),
+	}
+	for _, survivor := range result.Survivors {
+		out.Survivors = append(out.Survivors, survivorOutput{
+			Prover:      survivor.Prover.URL(),
+			BlockNumber: survivor.State.L2BlockNumber,
+			BlockHash:   survivor.State.L2BlockHash,
+		})
+	}
+	return out
+}
+
+func selectProver(client *lightclient.LightClient, index int) (*lightclient.ArbitrumClient, error) {
+	provers := client.Provers()
+	if index < 0 || index >= len(provers) {
+		return nil, fmt.Errorf("--prover %d out of range, %d provers configured", index, len(provers))
+	}
+	return provers[index], nil
+}
+
+func runVerifyHead(ctx context.Context, args []string) error {
+	fs := newFlagSet("verify-head")
+	client, err := parseAndConnect(ctx, fs, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	head, err := client.VerifyHead(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(newTournamentOutput(head.Anchor, head.Tournament, 0))
+}
+
+func runFollow(ctx context.Context, args []string) error {
+	fs := newFlagSet("follow")
+	client, err := parseAndConnect(ctx, fs, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	err = client.Follow(ctx, nil, func(head *lightclient.Head) error {
+		return writeJSON(newTournamentOutput(head.Anchor, head.Tournament, 0))
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+func runTournament(ctx context.Context, args []string) error {
+	fs := newFlagSet("tournament")
+	at := fs.Uint64("at", 0, "block number at which the provers are compared (0 compares their latest states)")
+	client, err := parseAndConnect(ctx, fs, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	assertion, err := client.LatestConfirmedAssertion(ctx)
+	if err != nil {
+		return err
+	}
+	anchor, err := client.VerifyAnchor(ctx, assertion)
+	if err != nil {
+		return err
+	}
+	result, err := client.Tournament(ctx, anchor, *at)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(newTournamentOutput(anchor, result, *at))
+}
+
+func runOracle(ctx context.Context, name string, args []string, oracle func(*lightclient.LightClient, context.Context, *lightclient.ArbitrumClient, uint64) (bool, error)) error {
+	fs := newFlagSet(name)
+	block := fs.Uint64("block", 0, "child chain block number to verify")
+	proverIndex := fs.Int("prover", 0, "index of the prover serving the block")
+	client, err := parseAndConnect(ctx, fs, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if *block == 0 {
+		return fmt.Errorf("--block is required")
+	}
+	prover, err := selectProver(client, *proverIndex)
+	if err != nil {
+		return err
+	}
+
+	verified, err := oracle(client, ctx, prover, *block)
+	out := oracleOutput{
+		Oracle:   name,
+		Prover:   prover.URL(),
+		Block:    *block,
+		Verified: verified,
+	}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	if err := writeJSON(out); err != nil {
+		return err
+	}
+	if !verified {
+		return fmt.Errorf("block %d not verified", *block)
+	}
+	return nil
+}
+
+func runConsensusOracle(ctx context.Context, args []string) error {
+	return runOracle(ctx, "consensus-oracle", args, (*lightclient.LightClient).VerifyConsensus)
+}
+
+func runExecutionOracle(ctx context.Context, args []string) error {
+	return runOracle(ctx, "execution-oracle", args, (*lightclient.LightClient).VerifyExecution)
+}
+
+func runMeasure(ctx context.Context, args []string) error {
+	fs := newFlagSet("measure")
+	client, err := parseAndConnect(ctx, fs, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected exactly one of tournament, consensus or execution")
+	}
+	kind := fs.Arg(0)
+
+	provers := client.Provers()
+	runner := lightclient.NewMeasurementRunner(ctx, client.Config(), provers[0])
+
+	switch kind {
+	case "tournament":
+		err = runner.RunTournamentMeasurements(provers)
+	case "consensus":
+		err = runner.RunConsensusOracleMeasurements()
+	case "execution":
+		err = runner.RunExecutionOracleMeasurements()
+	default:
+		return fmt.Errorf("unknown measurement %q, expected tournament, consensus or execution", kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(measureOutput{Kind: kind, Summary: runner.Summary()})
+}
+
+func runAssertion(ctx context.Context, args []string) error {
+	fs := newFlagSet("assertion")
+	count := fs.Int("count", 10, "number of confirmed assertions before the latest one to show")
+	config, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if *count < 0 {
+		return fmt.Errorf("count must not be negative, got %d", *count)
+	}
+	if err := setup(config); err != nil {
+		return err
+	}
+	client, err := lightclient.New(ctx, &config.Config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if fs.NArg() == 1 && fs.Arg(0) == "history" {
+		history, err := client.AssertionHistory(ctx, *count)
+		if err != nil {
+			return err
+		}
+		return writeJSON(history)
+	}
+	if fs.NArg() != 1 || (fs.Arg(0) != "show" && fs.Arg(0) != "pending") {
+		return fmt.Errorf("expected subcommand show, history or pending")
+	}
+
+	assertion, err := client.LatestConfirmedAssertion(ctx)
+	if err != nil {
+		return err
+	}
+	confirmed := newAssertionOutput(assertion)
+
+	if fs.Arg(0) == "pending" {
+		pending, err := client.PendingAssertions(ctx, assertion)
+		if err != nil {
+			return err
+		}
+		return writeJSON(pendingOutput{Confirmed: confirmed, Pending: pending})
+	}
+	return writeJSON(confirmed)
+}
+
+func newAssertionOutput(assertion *lightclient.ConfirmedAssertion) assertionOutput {
+	return assertionOutput{
+		Hash:                assertion.Hash,
+		ParentAssertionHash: assertion.Created.ParentAssertionHash,
+		Status:              assertion.Node.Status,
+		CreatedAtBlock:      assertion.Node.CreatedAtBlock,
+		ConfirmedAtBlock:    assertion.Confirmed.Raw.BlockNumber,
+		BlockHash:           assertion.Confirmed.BlockHash,
+		SendRoot:            assertion.Confirmed.SendRoot,
+		Batch:               assertion.AfterState().Batch(),
+		PosInBatch:          assertion.AfterState().PosInBatch(),
+		AfterInboxBatchAcc:  assertion.Created.AfterInboxBatchAcc,
+		InboxMaxCount:       assertion.Created.InboxMaxCount.String(),
+		WasmModuleRoot:      assertion.Created.WasmModuleRoot,
+		ConfirmPeriodBlocks: assertion.Created.ConfirmPeriodBlocks,
+		Trust:               lightclient.TrustConfirmed,
+	}
+}
+
+func runWithdrawal(ctx context.Context, args []string) error {
+	fs := newFlagSet("withdrawal")
+	client, err := parseAndConnect(ctx, fs, args)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a transaction hash")
+	}
+	txHash := common.HexToHash(fs.Arg(0))
+
+	assertion, err := client.LatestConfirmedAssertion(ctx)
+	if err != nil {
+		return err
+	}
+	anchor, err := client.VerifyAnchor(ctx, assertion)
+	if err != nil {
+		return err
+	}
+	proofs, err := client.ProveWithdrawals(ctx, anchor, txHash)
+	if err != nil {
+		return err
+	}
+	return writeJSON(proofs)
+}
+
+// runHistory answers from the store only, without contacting any node.
+func runHistory(ctx context.Context, args []string) error {
+	fs := newFlagSet("history")
+	limit := fs.Int("limit", 10, "maximum number of tournament outcomes to show (0 shows all)")
+	// Only the store is read, so the network settings don't need to be set
+	config, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := setupLogging(config.LogLevel); err != nil {
+		return err
+	}
+	if config.Store.Dir == "" {
+		return fmt.Errorf("history requires --store.dir")
+	}
+	store, err := lightclient.OpenStore(config.Store.Dir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	switch {
+	case fs.NArg() == 1 && fs.Arg(0) == "tournaments":
+		records, err := store.Tournaments(*limit)
+		if err != nil {
+			return err
+		}
+		return writeJSON(records)
+	case fs.NArg() == 1 && fs.Arg(0) == "assertion":
+		hash, err := store.LatestAssertion()
+		if err != nil {
+			return err
+		}
+		assertion, err := store.Assertion(hash)
+		if err != nil {
+			return err
+		}
+		return writeJSON(assertion)
+	case fs.NArg() == 2 && fs.Arg(0) == "header":
+		var header *types.Header
+		if number, err := strconv.ParseUint(fs.Arg(1), 10, 64); err == nil {
+			header, err = store.HeaderByNumber(number)
+			if err != nil {
+				return err
+			}
+		} else {
+			header, err = store.HeaderByHash(common.HexToHash(fs.Arg(1)))
+			if err != nil {
+				return err
+			}
+		}
+		return writeJSON(header)
+	default:
+		return fmt.Errorf("expected tournaments, assertion or header <number|hash>")
+	}
+}
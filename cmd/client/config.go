@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/env"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/providers/posflag"
+	flag "github.com/spf13/pflag"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient"
+)
+
+type ClientConfig struct {
+	Conf               ConfConfig   `koanf:"conf"`
+	LogLevel           string       `koanf:"log-level"`
+	Serve              ServeConfig  `koanf:"serve"`
+	Replay             ReplayConfig `koanf:"replay"`
+	lightclient.Config `koanf:",squash"`
+}
+
+type ConfConfig struct {
+	File      []string `koanf:"file"`
+	EnvPrefix string   `koanf:"env-prefix"`
+}
+
+var DefaultConfConfig = ConfConfig{
+	File:      []string{},
+	EnvPrefix: "LIGHTCLIENT",
+}
+
+// legacyEnvVars maps the variables of the original .env setup to config keys.
+var legacyEnvVars = map[string]string{
+	"ETHEREUM_RPC_URL":        "parent-chain.url",
+	"ETHEREUM_BEACON_RPC_URL": "parent-chain.beacon-url",
+	"PROVERS":                 "provers",
+	"ROLLUP_CORE_ADDRESS":     "chain.rollup-core-address",
+	"ARBITRUM_ONE_CHAIN_ID":   "chain.id",
+}
+
+func ConfConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.StringSlice(prefix+".file", DefaultConfConfig.File, "YAML or JSON config files, later files override earlier ones")
+	f.String(prefix+".env-prefix", DefaultConfConfig.EnvPrefix, "prefix of environment variables overriding the config (e.g. LIGHTCLIENT_PARENT__CHAIN_URL for parent-chain.url)")
+}
+
+func ClientConfigAddOptions(f *flag.FlagSet) {
+	ConfConfigAddOptions("conf", f)
+	f.String("log-level", "warn", "log level written to stderr (trace, debug, info, warn, error, crit)")
+	ServeConfigAddOptions("serve", f)
+	ReplayConfigAddOptions("replay", f)
+	lightclient.ConfigAddOptions(f)
+}
+
+func (c *ClientConfig) Validate() error {
+	var errs []error
+	if err := c.Config.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Serve.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("serve: %w", err))
+	}
+	if err := c.Replay.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("replay: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// parseConfig builds the config from, in increasing order of precedence,
+// flag defaults, config files, the legacy .env variables, prefixed
+// environment variables and explicitly set flags, and validates it.
+func parseConfig(f *flag.FlagSet, args []string) (*ClientConfig, error) {
+	config, err := loadConfig(f, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return config, nil
+}
+
+// loadConfig builds the config like parseConfig but leaves validation to the
+// caller, for commands that don't connect to any chain.
+func loadConfig(f *flag.FlagSet, args []string) (*ClientConfig, error) {
+	ClientConfigAddOptions(f)
+	if err := f.Parse(args); err != nil {
+		return nil, err
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(posflag.Provider(f, ".", k), nil); err != nil {
+		return nil, fmt.Errorf("error loading flags: %w", err)
+	}
+
+	for _, path := range k.Strings("conf.file") {
+		parser, err := configParser(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := k.Load(file.Provider(path), parser); err != nil {
+			return nil, fmt.Errorf("error loading config file %s: %w", path, err)
+		}
+	}
+
+	if err := loadLegacyEnv(k); err != nil {
+		return nil, err
+	}
+
+	if prefix := k.String("conf.env-prefix"); prefix != "" {
+		if err := loadEnv(k, prefix+"_"); err != nil {
+			return nil, err
+		}
+	}
+
+	// Reload the flags so that explicitly set ones override files and environment
+	if err := k.Load(posflag.Provider(f, ".", k), nil); err != nil {
+		return nil, fmt.Errorf("error loading flags: %w", err)
+	}
+
+	var config ClientConfig
+	if err := k.UnmarshalWithConf("", &config, koanf.UnmarshalConf{Tag: "koanf"}); err != nil {
+		return nil, fmt.Errorf("error decoding config: %w", err)
+	}
+
+	return &config, nil
+}
+
+func configParser(path string) (koanf.Parser, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Parser(), nil
+	case ".json":
+		return json.Parser(), nil
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension, expected .yaml, .yml or .json", path)
+	}
+}
+
+func loadLegacyEnv(k *koanf.Koanf) error {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error loading .env file: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	for name, key := range legacyEnvVars {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			continue
+		}
+		if key == "provers" {
+			values[key] = strings.Split(value, ",")
+		} else {
+			values[key] = value
+		}
+	}
+
+	if err := k.Load(confmap.Provider(values, "."), nil); err != nil {
+		return fmt.Errorf("error loading legacy environment variables: %w", err)
+	}
+	return nil
+}
+
+func loadEnv(k *koanf.Koanf, prefix string) error {
+	provider := env.ProviderWithValue(prefix, ".", func(key string, value string) (string, interface{}) {
+		key = strings.ToLower(strings.TrimPrefix(key, prefix))
+		key = strings.ReplaceAll(key, "__", "-")
+		key = strings.ReplaceAll(key, "_", ".")
+		if key == "provers" {
+			return key, strings.Split(value, ",")
+		}
+		return key, value
+	})
+	if err := k.Load(provider, nil); err != nil {
+		return fmt.Errorf("error loading environment variables: %w", err)
+	}
+	return nil
+}
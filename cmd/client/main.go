@@ -2,358 +2,115 @@ package main
 
 import (
 	"context"
-	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
-	"math/big"
 	"os"
-	"sort"
-	"strconv"
-	"strings"
+	"os/signal"
+	"syscall"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/joho/godotenv"
-	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/ethereum/go-ethereum/log"
+	flag "github.com/spf13/pflag"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient"
 )
 
-type ChallengeResult int
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
 
-const (
-	BothLose ChallengeResult = iota
-	LargestLosesParticipantWins
-	LargestWinsParticipantLoses
-	BothWin
-)
+var commands = []*command{
+	{name: "verify-head", usage: "verify the latest confirmed assertion and run the tournament over the provers' latest states", run: runVerifyHead},
+	{name: "follow", usage: "keep verifying the head as new assertions get confirmed, printing every new head", run: runFollow},
+	{name: "tournament", usage: "run the tournament with all provers compared at block --at", run: runTournament},
+	{name: "consensus-oracle", usage: "run the consensus oracle for --block", run: runConsensusOracle},
+	{name: "execution-oracle", usage: "run the execution oracle for --block", run: runExecutionOracle},
+	{name: "measure", usage: "run measurements: measure tournament|consensus|execution", run: runMeasure},
+	{name: "assertion", usage: "inspect assertions: assertion show|history [--count]|pending", run: runAssertion},
+	{name: "withdrawal", usage: "prove the L2 to L1 messages of a transaction against the confirmed send root: withdrawal <tx hash>", run: runWithdrawal},
+	{name: "history", usage: "query the store: history tournaments|assertion|header <number|hash>", run: runHistory},
+	{name: "serve", usage: "serve a JSON-RPC proxy answering only with data verified against the tournament head", run: runServe},
+}
 
 func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatalf("Error loading .env file: %v", err)
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
 	}
 
-	ctx := context.Background()
-
-	ethRpcURL := os.Getenv("ETHEREUM_RPC_URL")
-	proverRpcURLs := strings.Split(os.Getenv("PROVERS"), ",")
-	rollupCoreAddr := common.HexToAddress(os.Getenv("ROLLUP_CORE_ADDRESS"))
-
-	ethClient, err := NewEthereumClient(ethRpcURL, rollupCoreAddr)
-	if err != nil {
-		log.Fatalf("Failed to init Ethereum client: %v", err)
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return
 	}
 
-	arbClients := make([]*ArbitrumClient, len(proverRpcURLs))
-	for i, proverRpcURL := range proverRpcURLs {
-		fmt.Printf("Initializing prover %d: %s\n", i, proverRpcURL)
-		arbClients[i], err = NewArbitrumClient(proverRpcURL)
-		if err != nil {
-			log.Fatalf("Failed to init Arbitrum client: %v", err)
+	var cmd *command
+	for _, c := range commands {
+		if c.name == name {
+			cmd = c
+			break
 		}
 	}
-
-	// // 1. Fetch latest confirmed assertion
-	latestAssertion, err := ethClient.GetLatestAssertion(ctx)
-	if err != nil {
-		log.Fatalf("Failed to get latest assertion: %v", err)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
 	}
-	fmt.Printf("Latest Confirmed Assertion: 0x%s\n", hex.EncodeToString(latestAssertion[:]))
 
-	// 2. Fetch assertion node details
-	assertionNode, err := ethClient.GetAssertionDetails(ctx, latestAssertion)
-	if err != nil {
-		log.Fatalf("Failed to get assertion details: %v", err)
-	}
-	fmt.Printf("Assertion: %+v\n", assertionNode)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
-	// 3. Get AssertionConfirmed log
-	confirmedLog, err := ethClient.GetAssertionConfirmedLog(ctx)
-	if err != nil {
-		log.Fatalf("Failed to get AssertionConfirmed: %v", err)
+	err := cmd.run(ctx, os.Args[2:])
+	if saveErr := saveRecording(); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, saveErr)
 	}
-	fmt.Printf("AssertionConfirmed: %+v\n", confirmedLog)
-
-	// 4. Get AssertionCreated log
-	createdLog, err := ethClient.GetAssertionCreatedLog(ctx)
 	if err != nil {
-		log.Fatalf("Failed to get AssertionCreated: %v", err)
-	}
-	fmt.Printf("AssertionCreated: %+v\n", createdLog)
-
-	fmt.Printf("AVM State root: 0x%x\n", createdLog.Assertion.AfterState.EndHistoryRoot)
-
-	var genesisBlock *types.Block
-
-	// discard all provers if they disagree on neon-genesis:
-	realArbClients := make([]*ArbitrumClient, 0)
-	for i := 0; i < len(arbClients); i++ {
-		block, err := arbClients[i].GetBlockByHash(ctx, confirmedLog.BlockHash)
-		if err != nil {
-			log.Fatalf("Failed to get L2 block by hash: %v", err)
-		}
-		verified := arbClients[i].VerifyBlockHash(block.Header(), confirmedLog.BlockHash)
-		fmt.Printf("Block number %d hash verified: %v\n", block.Header().Number.Uint64(), verified)
-		if verified {
-			realArbClients = append(realArbClients, arbClients[i])
-		}
-		if genesisBlock == nil && verified {
-			genesisBlock = block
+		if errors.Is(err, flag.ErrHelp) {
+			return
 		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+		os.Exit(1)
 	}
-
-	for i := 0; i < len(realArbClients); i++ {
-		fmt.Printf("Agrees on genesis client %d: %p\n", i, realArbClients[i])
-	}
-
-	beaconRpcURL := os.Getenv("ETHEREUM_BEACON_RPC_URL")
-	arbChainId, err := strconv.ParseUint(os.Getenv("ARBITRUM_ONE_CHAIN_ID"), 10, 64)
-
-	if err != nil {
-		log.Fatalf("Error parsing ARBITRUM_CHAIN_ID: %v", err)
-	}
-
-	// RunMeasurements(ctx, arbClients, ethRpcURL, arbChainId, beaconRpcURL)
-
-	Tournament(ctx, *genesisBlock.Header(), arbClients, ethRpcURL, arbChainId, beaconRpcURL, 0)
-
 }
 
-func RunMeasurements(ctx context.Context, arbClients []*ArbitrumClient, ethRpcURL string, arbChainId uint64, beaconRpcURL string) {
-	// Example 1: Tournament measurements
-	fmt.Println("\n1. Running Tournament measurements...")
-	config1 := &MeasurementConfig{
-		NumIterations:  5,
-		OutputDir:      "./measurements/tournament",
-		MeasureSystem:  true,
-		MeasureNetwork: true,
-	}
-
-	runner1 := NewMeasurementRunner(config1, arbClients[0], ctx, beaconRpcURL, ethRpcURL, arbChainId)
-	if err := runner1.RunTournamentMeasurements(arbClients); err != nil {
-		log.Printf("Tournament measurements failed: %v", err)
-	}
-	runner1.PrintSummary()
-
-	// Example 2: Consensus oracle measurements
-	fmt.Println("\n2. Running Consensus Oracle measurements...")
-	config2 := &MeasurementConfig{
-		NumIterations:  10,
-		OutputDir:      "./measurements/consensus",
-		MeasureSystem:  true,
-		MeasureNetwork: true,
-	}
-
-	runner2 := NewMeasurementRunner(config2, arbClients[0], ctx, beaconRpcURL, ethRpcURL, arbChainId)
-	if err := runner2.RunConsensusOracleMeasurements(); err != nil {
-		log.Printf("Consensus oracle measurements failed: %v", err)
-	}
-	runner2.PrintSummary()
-
-	// Example 3: Execution oracle measurements
-	fmt.Println("\n3. Running Execution Oracle measurements...")
-	config3 := &MeasurementConfig{
-		NumIterations:  10,
-		OutputDir:      "./measurements/execution",
-		MeasureSystem:  true,
-		MeasureNetwork: true,
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", c.name, c.usage)
 	}
-
-	runner3 := NewMeasurementRunner(config3, arbClients[0], ctx, beaconRpcURL, ethRpcURL, arbChainId)
-	if err := runner3.RunExecutionOracleMeasurements(); err != nil {
-		log.Printf("Execution oracle measurements failed: %v", err)
-	}
-	runner3.PrintSummary()
 }
 
-func TestOracles(arbClient *ArbitrumClient, index uint64, ctx context.Context, beaconRpcURL string, ethClientUrl string, arbChainId uint64) bool {
-	prevBlock, err := arbClient.GetBlockByNumber(ctx, big.NewInt(int64(index-1)))
-	if err != nil {
-		log.Fatalf("Failed to get block: %v", err)
-	}
-	currBlock, err := arbClient.GetBlockByNumber(ctx, big.NewInt(int64(index)))
-	if err != nil {
-		log.Fatalf("Failed to get block: %v", err)
-	}
-
-	prevTrackingL1Data := arbClient.GetL1DataAt(ctx, index, arbChainId)
-	currTrackingL1Data := arbClient.GetL1DataAt(ctx, index+1, arbChainId)
-
-	if prevTrackingL1Data.Message.Header.Kind == arbostypes.L1MessageType_Initialize {
-		consensussOracleResult, err := ExecuteConsensusOracle(ctx, prevTrackingL1Data, currTrackingL1Data, ethClientUrl, arbChainId, beaconRpcURL)
-		if err != nil || !consensussOracleResult {
-			return false
-		}
-
-		executionOracleResult := ExecuteExecutionOracle(ctx, arbClient, prevBlock.Header(), &prevTrackingL1Data.Message, currBlock.Header(), arbChainId, &currTrackingL1Data.Message)
-
-		return executionOracleResult
-	} else {
-		consensusOracleResult, err := ExecuteConsensusOracle(ctx, prevTrackingL1Data, currTrackingL1Data, ethClientUrl, arbChainId, beaconRpcURL)
-
-		if !consensusOracleResult || err != nil {
-			return false
-		}
-
-		executionOracleResult := ExecuteExecutionOracle(ctx, arbClient, prevBlock.Header(), &currTrackingL1Data.Message, currBlock.Header(), arbChainId)
-
-		return executionOracleResult
-	}
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
 }
 
-func Tournament(ctx context.Context, neonGenesisBlock types.Header, arbClients []*ArbitrumClient, ethClientUrl string, arbChainId uint64, beaconRpcURL string, n uint64) {
-	sizes := make(map[*ArbitrumClient]MessageTrackingL2Data)
-
-	for i := 0; i < len(arbClients); i++ {
-		var state *MessageTrackingL2Data
-		var err error
-		if n == 0 {
-			state, err = arbClients[i].GetLatestState(ctx, arbChainId)
-		} else {
-			state, err = arbClients[i].GetStateAt(ctx, n, arbChainId)
-		}
-		sizes[arbClients[i]] = *state
-		if err != nil {
-			log.Fatalf("Failed to get latest state: %v", err)
-		}
-	}
-
-	sort.Slice(arbClients, func(i, j int) bool {
-		return sizes[arbClients[i]].L2BlockNumber > sizes[arbClients[j]].L2BlockNumber
-	})
-
-	S := make(map[*ArbitrumClient]bool)
-	S[arbClients[0]] = true
-
-	largest := arbClients[0]
-
-	for i := 1; i < len(arbClients); i++ {
-		participant := arbClients[i]
-
-		for {
-			result := Challenge(neonGenesisBlock, largest, sizes[largest], participant, sizes[participant], ctx, ethClientUrl, arbChainId, beaconRpcURL)
-
-			if result == BothWin {
-				S[participant] = true
-				break
-			} else if result == BothLose || result == LargestLosesParticipantWins {
-				delete(S, largest)
-
-				var newLargest *ArbitrumClient
-				var maxSize uint64
-				for survivor := range S {
-					if sizes[survivor].L2BlockNumber > maxSize {
-						maxSize = sizes[survivor].L2BlockNumber
-						newLargest = survivor
-					}
-				}
-				largest = newLargest
-
-				if largest != nil {
-					continue
-				} else {
-					break
-				}
-			} else {
-				break
-			}
-		}
-
-		if len(S) == 0 {
-			S[participant] = true
-			largest = participant
-		}
-	}
-
-	fmt.Println("Final survivors:", len(S))
-	for survivor := range S {
-		fmt.Printf("Final survivor: %p, Size: %d, Hash: %s\n", survivor, sizes[survivor].L2BlockNumber, sizes[survivor].L2BlockHash.Hex())
+func setupLogging(level string) error {
+	lvl, err := log.LvlFromString(level)
+	if err != nil {
+		return fmt.Errorf("invalid log-level %q: %w", level, err)
 	}
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, lvl, false)))
+	return nil
 }
 
-func Challenge(neonGenesisBlock types.Header, largest *ArbitrumClient, largestState MessageTrackingL2Data, participant *ArbitrumClient, participantState MessageTrackingL2Data, ctx context.Context, ethClientUrl string, arbChainId uint64, beaconRpcURL string) ChallengeResult {
-	fmt.Printf("=== Challenge between largest (size: %d) and participant (size: %d) ===\n",
-		largestState.L2BlockNumber, participantState.L2BlockNumber)
-
-	largestStateLower, err := largest.GetBlockByNumber(ctx, big.NewInt(int64(participantState.L2BlockNumber)))
+// parseAndConnect parses the config, sets up logging and returns a light
+// client built from it.
+func parseAndConnect(ctx context.Context, fs *flag.FlagSet, args []string) (*lightclient.LightClient, error) {
+	config, err := parseConfig(fs, args)
 	if err != nil {
-		fmt.Printf("Largest client failed to get block %d: %v\n", participantState.L2BlockNumber, err)
-		return LargestLosesParticipantWins
+		return nil, err
 	}
-
-	if largestStateLower.Header().Hash() == participantState.L2BlockHash {
-		fmt.Println("Both clients agree on common prefix")
-
-		// If they agree on common prefix, participant is not losing
-		// Now test the remaining blocks of the larger client
-		fmt.Printf("Testing remaining blocks %d to %d\n", participantState.L2BlockNumber+1, largestState.L2BlockNumber)
-
-		for index := participantState.L2BlockNumber + 1; index < min(largestState.L2BlockNumber, participantState.L2BlockNumber+10); index++ {
-			fmt.Printf("Testing block %d\n", index)
-
-			result := TestOracles(largest, index, ctx, beaconRpcURL, ethClientUrl, arbChainId)
-			if !result {
-				return LargestLosesParticipantWins
-			}
-		}
-
-		fmt.Println("Largest client passed all tests")
-		return BothWin
-
-	} else {
-		fmt.Printf("Disagreement found! largest=%s, participant=%s\n",
-			largestState.L2BlockHash.Hex(), participantState.L2BlockHash.Hex())
-
-		if largestStateLower.Header().Number.Uint64() != participantState.L2BlockNumber {
-			return LargestLosesParticipantWins
-		}
-
-		// Perform bisection to find a point of disagreement
-		return PerformBisection(neonGenesisBlock, largest, participant, participantState, ctx, ethClientUrl, arbChainId, beaconRpcURL)
+	if err := setup(config); err != nil {
+		return nil, err
 	}
+	return lightclient.New(ctx, &config.Config)
 }
 
-func PerformBisection(neonGenesisBlock types.Header, largest *ArbitrumClient, participant *ArbitrumClient, participantState MessageTrackingL2Data, ctx context.Context, ethClientUrl string, arbChainId uint64, beaconRpcURL string) ChallengeResult {
-	left := neonGenesisBlock.Number.Uint64()
-	right := participantState.L2BlockNumber
-
-	for left < right-1 {
-		mid := (left + right) / 2
-		largestState, err := largest.GetBlockByNumber(ctx, big.NewInt(int64(mid)))
-		if err != nil {
-			fmt.Printf("Largest client failed to get block %d: %v\n", mid, err)
-			return LargestLosesParticipantWins
-		}
-		participantState, err := participant.GetBlockByNumber(ctx, big.NewInt(int64(mid)))
-		if err != nil {
-			fmt.Printf("Participant client failed to get block %d: %v\n", mid, err)
-			return LargestWinsParticipantLoses
-		}
-		if largestState.Header().Hash() == participantState.Header().Hash() {
-			fmt.Println("updating left to", mid)
-			left = mid
-		} else {
-			fmt.Println("updating right to", mid)
-			right = mid
-		}
-	}
-
-	// Now test the disagreement point
-	fmt.Printf("Testing disagreement at block %d\n", right)
-
-	largestResult := TestOracles(largest, right, ctx, beaconRpcURL, ethClientUrl, arbChainId)
-	participantResult := TestOracles(participant, right, ctx, beaconRpcURL, ethClientUrl, arbChainId)
-
-	if largestResult && !participantResult {
-		fmt.Println("Largest wins, participant loses")
-		return LargestWinsParticipantLoses
-	} else if !largestResult && participantResult {
-		fmt.Println("Largest loses, participant wins")
-		return LargestLosesParticipantWins
-	} else if !largestResult && !participantResult {
-		fmt.Println("Both lose")
-		return BothLose
-	} else {
-		fmt.Println("Both win (shouldn't happen with disagreement)")
-		return BothWin
+// setup applies the process wide settings of config.
+func setup(config *ClientConfig) error {
+	if err := setupLogging(config.LogLevel); err != nil {
+		return err
 	}
+	return setupReplay(config)
 }
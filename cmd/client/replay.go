@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/replay"
+)
+
+type ReplayConfig struct {
+	Record string `koanf:"record"`
+	From   string `koanf:"from"`
+}
+
+var DefaultReplayConfig = ReplayConfig{
+	Record: "",
+	From:   "",
+}
+
+func ReplayConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".record", DefaultReplayConfig.Record, "record all HTTP JSON-RPC and beacon traffic of the run to this bundle file")
+	f.String(prefix+".from", DefaultReplayConfig.From, "answer all HTTP JSON-RPC and beacon requests from this bundle file instead of the network")
+}
+
+func (c *ReplayConfig) Validate() error {
+	if c.Record != "" && c.From != "" {
+		return errors.New("record and from are mutually exclusive")
+	}
+	return nil
+}
+
+// recorder is set while the run is being recorded, so main can write the
+// bundle once the command returns.
+var recorder *replay.Recorder
+var recordPath string
+
+// setupReplay sets a recording or replaying transport as the transport of the
+// light client. It also becomes the default HTTP transport, which the blob
+// client and the DAS REST client always use. Config validation then rejects
+// WebSocket endpoints, whose traffic could not be covered.
+func setupReplay(config *ClientConfig) error {
+	var transport http.RoundTripper
+	switch {
+	case config.Replay.Record != "":
+		recorder = replay.NewRecorder(http.DefaultTransport)
+		recordPath = config.Replay.Record
+		transport = recorder
+	case config.Replay.From != "":
+		bundle, err := replay.LoadBundle(config.Replay.From)
+		if err != nil {
+			return err
+		}
+		replayer, err := replay.NewReplayer(bundle)
+		if err != nil {
+			return fmt.Errorf("invalid bundle %s: %w", config.Replay.From, err)
+		}
+		transport = replayer
+	default:
+		return nil
+	}
+	config.Transport = transport
+	http.DefaultTransport = transport
+	return nil
+}
+
+func saveRecording() error {
+	if recorder == nil {
+		return nil
+	}
+	if err := recorder.Bundle().Save(recordPath); err != nil {
+		return fmt.Errorf("failed to save recording to %s: %w", recordPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	flag "github.com/spf13/pflag"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient"
+)
+
+type ServeConfig struct {
+	Addr       string   `koanf:"addr"`
+	CORSDomain []string `koanf:"cors-domain"`
+	VHosts     []string `koanf:"vhosts"`
+	Follow     bool     `koanf:"follow"`
+}
+
+var DefaultServeConfig = ServeConfig{
+	Addr:       "127.0.0.1:8548",
+	CORSDomain: []string{},
+	VHosts:     []string{"localhost"},
+	Follow:     false,
+}
+
+func ServeConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".addr", DefaultServeConfig.Addr, "address the verifying JSON-RPC proxy listens on")
+	f.StringSlice(prefix+".cors-domain", DefaultServeConfig.CORSDomain, "origins allowed to make cross-origin requests to the proxy")
+	f.StringSlice(prefix+".vhosts", DefaultServeConfig.VHosts, "virtual hostnames accepted by the proxy (* accepts all)")
+	f.Bool(prefix+".follow", DefaultServeConfig.Follow, "keep moving the head the proxy answers against as new assertions get confirmed")
+}
+
+func (c *ServeConfig) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		return fmt.Errorf("addr: %w", err)
+	}
+	return nil
+}
+
+func runServe(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve")
+	config, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := setup(config); err != nil {
+		return err
+	}
+	client, err := lightclient.New(ctx, &config.Config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	head, err := client.VerifyHead(ctx)
+	if err != nil {
+		return err
+	}
+	survivor := head.Tournament.Head()
+	if survivor == nil {
+		return fmt.Errorf("%w: no prover survived the tournament", lightclient.ErrNoHonestProver)
+	}
+
+	proxy, err := lightclient.NewVerifyingProxy(client)
+	if err != nil {
+		return err
+	}
+	defer proxy.Stop()
+	proxy.SetHead(head)
+
+	server := &http.Server{
+		Addr:              config.Serve.Addr,
+		Handler:           node.NewHTTPHandlerStack(proxy.Handler(), config.Serve.CORSDomain, config.Serve.VHosts, nil),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	listener, err := net.Listen("tcp", config.Serve.Addr)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+	if config.Serve.Follow {
+		go func() {
+			err := client.Follow(ctx, head, func(head *lightclient.Head) error {
+				proxy.SetHead(head)
+				return nil
+			})
+			if !errors.Is(err, context.Canceled) {
+				errCh <- err
+			}
+		}()
+	}
+	log.Info("Verifying proxy listening", "addr", listener.Addr(), "block", survivor.State.L2BlockNumber, "hash", survivor.State.L2BlockHash, "prover", survivor.Prover.URL())
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
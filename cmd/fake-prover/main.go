@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/fakeprover"
+)
+
+func main() {
+	fixtures := flag.String("fixtures", "./fixtures", "directory of JSON fixture files to serve")
+	addr := flag.String("addr", "127.0.0.1:8547", "address to listen on")
+	flag.Parse()
+
+	server, err := fakeprover.NewFromDir(*fixtures)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load fixtures: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Fake prover serving %s on %s\n", *fixtures, *addr)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
@@ -1,10 +1,11 @@
-package main
+package lightclient
 
 import (
 	"context"
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"net/http"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -14,18 +15,21 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/holiman/uint256"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 )
 
 type ArbitrumClient struct {
-	ethClient *ethclient.Client
-	rpcClient *rpc.Client
+	url         string
+	ethClient   *ethclient.Client
+	rpcClient   *rpc.Client
+	codeCache   *CodeCache
+	batchCache  *BatchCache        // nil disables caching of decoded batches
+	parentChain *ParentChainClient // nil makes every batch dial the parent chain
+	dasReader   *DASReader         // nil makes every batch create its own, if enabled
 }
 
 type MessageTrackingL2Data struct {
@@ -45,17 +49,40 @@ type L1Index struct {
 
 const ARBITRUM_ONE_GENESIS_BLOCK = 22207817
 
-func NewArbitrumClient(rpcURL string) (*ArbitrumClient, error) {
-	ethClient, err := ethclient.Dial(rpcURL)
+// NewArbitrumClient connects to the prover at rpcURL. transport carries the
+// requests instead of http.DefaultTransport if it is not nil, which needs an
+// HTTP endpoint.
+func NewArbitrumClient(ctx context.Context, rpcURL string, transport http.RoundTripper) (*ArbitrumClient, error) {
+	var options []rpc.ClientOption
+	if transport != nil {
+		if !isHTTPURL(rpcURL) {
+			return nil, ErrTransportNeedsHTTP
+		}
+		options = append(options, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+	rpcClient, err := rpc.DialOptions(ctx, rpcURL, options...)
 	if err != nil {
 		return nil, err
 	}
-	rpcClient, err := rpc.Dial(rpcURL)
+	ethClient := ethclient.NewClient(rpcClient)
+
+	codeCache, err := NewCodeCache(defaultCodeCacheSize)
 	if err != nil {
+		rpcClient.Close()
 		return nil, err
 	}
 
-	return &ArbitrumClient{ethClient: ethClient, rpcClient: rpcClient}, nil
+	return &ArbitrumClient{url: rpcURL, ethClient: ethClient, rpcClient: rpcClient, codeCache: codeCache}, nil
+}
+
+// URL returns the RPC endpoint of the prover.
+func (c *ArbitrumClient) URL() string {
+	return c.url
+}
+
+// Close tears down the underlying RPC connection, which the eth client shares.
+func (c *ArbitrumClient) Close() {
+	c.rpcClient.Close()
 }
 
 func (c *ArbitrumClient) GetBlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
@@ -105,7 +132,6 @@ func (c *ArbitrumClient) GetProof(ctx context.Context, blockNumber big.Int, addr
 func (c *ArbitrumClient) VerifyBlockHash(header *types.Header, expectedHash common.Hash) bool {
 	encoded, err := rlp.EncodeToBytes(header)
 	if err != nil {
-		fmt.Println("RLP encoding failed:", err)
 		return false
 	}
 	actualHash := crypto.Keccak256Hash(encoded)
@@ -117,109 +143,6 @@ type AccessList struct {
 	StorageKeys []common.Hash
 }
 
-func (c *ArbitrumClient) VerifyStateProof(stateRoot common.Hash, proof *EthGetProofResult) bool {
-	addr := common.HexToAddress(proof.Address)
-	key := crypto.Keccak256(addr.Bytes())
-
-	for _, sp := range proof.StorageProofs {
-		slotKeyBytes, err := hex.DecodeString(trimHexPrefix(sp.Key))
-		if err != nil {
-			fmt.Println("❌ Invalid storage key:", err)
-			return false
-		}
-		slotKeyHash := crypto.Keccak256(slotKeyBytes)
-
-		storageDB := memorydb.New()
-		for _, encodedNode := range sp.Proof {
-			nodeBytes, err := hex.DecodeString(trimHexPrefix(encodedNode))
-			if err != nil {
-				fmt.Println("❌ Failed to decode storage proof node:", err)
-				return false
-			}
-			hash := crypto.Keccak256Hash(nodeBytes)
-			if err := storageDB.Put(hash.Bytes(), nodeBytes); err != nil {
-				fmt.Println("❌ Failed to insert storage proof node:", err)
-				return false
-			}
-		}
-
-		expectedValue := new(big.Int)
-		expectedValue.SetString(trimHexPrefix(sp.Value), 16)
-
-		val, err := trie.VerifyProof(common.HexToHash(proof.StorageHash), slotKeyHash, storageDB)
-		if err != nil {
-			fmt.Println("❌ Storage proof verification failed:", err)
-			return false
-		}
-
-		var decodedValue *big.Int
-		if len(val) == 0 {
-			decodedValue = big.NewInt(0)
-		} else {
-			decodedValue = new(big.Int)
-			if err := rlp.DecodeBytes(val, decodedValue); err != nil {
-				fmt.Println("❌ Failed to decode storage value:", err)
-				return false
-			}
-		}
-
-		if decodedValue.Cmp(expectedValue) != 0 {
-			fmt.Printf("❌ Storage slot mismatch: got %s, expected %s\n", decodedValue, expectedValue)
-			return false
-		}
-	}
-
-	proofDB := memorydb.New()
-	for _, encodedNode := range proof.AccountProof {
-		nodeBytes, err := hex.DecodeString(trimHexPrefix(encodedNode))
-		if err != nil {
-			fmt.Println("❌ Failed to decode node:", err)
-			return false
-		}
-		hash := crypto.Keccak256Hash(nodeBytes)
-		if err := proofDB.Put(hash.Bytes(), nodeBytes); err != nil {
-			fmt.Println("❌ Failed to insert into DB:", err)
-			return false
-		}
-	}
-
-	val, err := trie.VerifyProof(stateRoot, key, proofDB)
-	if err != nil {
-		fmt.Println("❌ Proof verification failed:", err)
-		return false
-	}
-
-	var decoded struct {
-		Nonce       *big.Int
-		Balance     *big.Int
-		StorageRoot []byte
-		CodeHash    []byte
-	}
-
-	if err := rlp.DecodeBytes(val, &decoded); err != nil {
-		fmt.Println("❌ Failed to decode account value:", err)
-		return false
-	}
-
-	jsonNonce, _ := new(big.Int).SetString(trimHexPrefix(proof.Nonce), 16)
-	jsonBalance, _ := new(big.Int).SetString(trimHexPrefix(proof.Balance), 16)
-	jsonCodeHash := common.HexToHash(proof.CodeHash)
-
-	matches := decoded.Nonce.Cmp(jsonNonce) == 0 &&
-		decoded.Balance.Cmp(jsonBalance) == 0 &&
-		common.BytesToHash(decoded.CodeHash) == jsonCodeHash
-
-	if matches {
-		fmt.Println("✅ Account proof verified!")
-	} else {
-		fmt.Println("❌ Account data mismatch!")
-		fmt.Printf("Decoded: nonce=%v, balance=%v, codeHash=%x\n", decoded.Nonce, decoded.Balance, decoded.CodeHash)
-		fmt.Printf("Expected: nonce=%v, balance=%v, codeHash=%s\n", jsonNonce, jsonBalance, jsonCodeHash.Hex())
-	}
-
-	return matches
-}
-
 func trimHexPrefix(s string) string {
 	if len(s) >= 2 && s[:2] == "0x" {
 		return s[2:]
@@ -229,16 +152,15 @@ func trimHexPrefix(s string) string {
 
 func (c *ArbitrumClient) GetLatestState(ctx context.Context, chainId uint64) (*MessageTrackingL2Data, error) {
 	var index L1Index
-	var blockNumber uint64
-	c.rpcClient.CallContext(ctx, &index, "lightclient_getLatestIndexL1")
+	if err := c.rpcClient.CallContext(ctx, &index, "lightclient_getLatestIndexL1"); err != nil {
+		return nil, fmt.Errorf("lightclient_getLatestIndexL1 failed: %w", err)
+	}
 
 	blockNumber, err := c.ethClient.BlockNumber(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	c.rpcClient.CallContext(ctx, &blockNumber, "eth_blockNumber")
-
 	realIndex := min(index.StateIndex-1, blockNumber)
 
 	return c.GetStateAt(ctx, realIndex, chainId)
@@ -260,11 +182,17 @@ func (c *ArbitrumClient) GetStateAt(ctx context.Context, blockNumber uint64, cha
 	return &data, nil
 }
 
-func (c *ArbitrumClient) GetL1DataAt(ctx context.Context, blockNumber uint64, chainId uint64) MessageTrackingL1Data {
+func (c *ArbitrumClient) GetL1DataAt(ctx context.Context, blockNumber uint64, chainId uint64) (*MessageTrackingL1Data, error) {
 	var data MessageTrackingL1Data
 
-	c.rpcClient.CallContext(ctx, &data, "lightclient_getL1DataAt", blockNumber)
-	return data
+	err := c.rpcClient.CallContext(ctx, &data, "lightclient_getL1DataAt", blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("lightclient_getL1DataAt failed: %w", err)
+	}
+	if data.Message.Header == nil {
+		return nil, fmt.Errorf("no L1 data for block %d", blockNumber)
+	}
+	return &data, nil
 }
 
 func (c *ArbitrumClient) ReconstructStateFromProofsAndTrace(ctx context.Context, currentHeader *types.Header, previousHeader *types.Header, chainId uint64) (*state.StateDB, map[common.Address]struct{}, map[common.Address]map[common.Hash]struct{}, error) {
@@ -351,40 +279,38 @@ func (c *ArbitrumClient) ReconstructStateFromProofsAndTrace(ctx context.Context,
 	}
 
 	memdb := rawdb.NewMemoryDatabase()
-	proofs := make(map[common.Address]*EthGetProofResult)
+	accounts := make(map[common.Address]*VerifiedAccount)
 
 	for addr := range allAccounts {
-		var storageKeys []string
+		var slots []common.Hash
 		if storageMap, exists := allStorageKeys[addr]; exists {
 			for key := range storageMap {
-				storageKeys = append(storageKeys, key.Hex())
+				slots = append(slots, key)
 			}
 		}
 
 		if addr == common.HexToAddress("0xa4b05fffffffffffffffffffffffffffffffffff") {
-			arbosStorageKeys := c.getAllPossibleArbOSStorageKeys()
-			storageKeys = append(storageKeys, arbosStorageKeys...)
-			for _, key := range arbosStorageKeys {
-				allStorageKeys[addr][common.HexToHash(key)] = true
+			for _, key := range c.getAllPossibleArbOSStorageKeys() {
+				slot := common.HexToHash(key)
+				slots = append(slots, slot)
+				allStorageKeys[addr][slot] = true
 			}
 		}
 
-		proof, err := c.GetProof(ctx, *previousHeader.Number, addr, storageKeys)
+		proof, err := c.GetProof(ctx, *previousHeader.Number, addr, hashesToHex(slots))
 		if err != nil {
 			return nil, nil, nil, fmt.Errorf("failed to get proof for %s: %w", addr, err)
 		}
 
-		if proof.Balance == "0x0" && proof.Nonce == "0x0" && proof.StorageHash == "0x0000000000000000000000000000000000000000000000000000000000000000" && proof.CodeHash == "0x0000000000000000000000000000000000000000000000000000000000000000" {
+		account, err := VerifyAccountProof(previousHeader, addr, slots, proof)
+		if err != nil {
+			return nil, nil, nil, &StateProofError{Prover: c.url, Account: addr, Err: err}
+		}
+		if !account.Exists {
 			continue
 		}
 
-		// if addr != common.HexToAddress("0x00000000000000000000000000000000000a4b05") {
-		// 	if !c.VerifyStateProof(previousHeader.Root, proof) {
-		// 		return nil, nil, nil, fmt.Errorf("state proof mismatch for account %s", addr.Hex())
-		// 	}
-		// }
-
-		proofs[addr] = proof
+		accounts[addr] = account
 
 		for _, encodedNode := range proof.AccountProof {
 			nodeBytes, err := hex.DecodeString(trimHexPrefix(encodedNode))
@@ -418,26 +344,21 @@ func (c *ArbitrumClient) ReconstructStateFromProofsAndTrace(ctx context.Context,
 		return nil, nil, nil, fmt.Errorf("failed to create statedb: %w", err)
 	}
 
-	for addr, proof := range proofs {
-		nonce, _ := new(big.Int).SetString(trimHexPrefix(proof.Nonce), 16)
-		balance, _ := new(big.Int).SetString(trimHexPrefix(proof.Balance), 16)
+	for addr, account := range accounts {
+		statedb.SetBalance(addr, uint256.MustFromBig(account.Balance), tracing.BalanceChangeUnspecified)
+		statedb.SetNonce(addr, account.Nonce, tracing.NonceChangeUnspecified)
 
-		statedb.SetBalance(addr, uint256.MustFromBig(balance), tracing.BalanceChangeUnspecified)
-		statedb.SetNonce(addr, nonce.Uint64(), tracing.NonceChangeUnspecified)
-
-		code, err := c.ethClient.CodeAt(ctx, addr, previousHeader.Number)
+		code, err := c.codeByHash(ctx, addr, account.CodeHash, previousHeader.Number)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to get code for account %s: %w", addr, err)
+			return nil, nil, nil, err
 		}
 
 		if len(code) > 0 {
 			statedb.SetCode(addr, code)
 		}
 
-		for _, sp := range proof.StorageProofs {
-			key := common.HexToHash(sp.Key)
-			val := common.HexToHash(sp.Value)
-			statedb.SetState(addr, key, val)
+		for slot, value := range account.Storage {
+			statedb.SetState(addr, slot, value)
 		}
 	}
 
@@ -463,119 +384,6 @@ func (c *ArbitrumClient) ReconstructStateFromProofsAndTrace(ctx context.Context,
 	return statedb, accountSet, slotSet, nil
 }
 
-func (c *ArbitrumClient) VerifyStateAgainstProofs(ctx context.Context, statedb *state.StateDB, accountSet map[common.Address]struct{}, slotSet map[common.Address]map[common.Hash]struct{}, expectedHeader *types.Header) error {
-	fmt.Printf("🔍 Verifying %d accounts and their storage slots against proofs...\n", len(accountSet))
-
-	statedb2, _, _, err := c.ReconstructStateFromProofsAndTrace(ctx, expectedHeader, expectedHeader, 412346)
-	if err != nil {
-		return fmt.Errorf("failed to reconstruct state from proofs and trace: %w", err)
-	}
-
-	// statedb2.GetTrie().DeleteAccount(common.HexToAddress("0xa4b05fffffffffffffffffffffffffffffffffff"))
-	// statedb.GetTrie().DeleteAccount(common.HexToAddress("0xa4b05fffffffffffffffffffffffffffffffffff"))
-
-	root1 := statedb.IntermediateRoot(false)
-	root2 := statedb2.IntermediateRoot(false)
-	fmt.Printf("📊 Overall State Roots:\n")
-	fmt.Printf("  - StateDB1: %s\n", root1.Hex())
-	fmt.Printf("  - StateDB2: %s\n", root2.Hex())
-	fmt.Printf("  - Match: %t\n", root1 == root2)
-
-	return nil
-}
-
-func (c *ArbitrumClient) FindStateDifferences(ctx context.Context, statedb *state.StateDB, accountSet map[common.Address]struct{}, expectedHeader *types.Header) error {
-	fmt.Printf("🔍 Finding all state differences against expected block %d...\n", expectedHeader.Number.Uint64())
-
-	localAccounts := make(map[common.Address]bool)
-	for addr := range accountSet {
-		localAccounts[addr] = true
-	}
-
-	fmt.Printf("📊 Local state has %d accounts\n", len(localAccounts))
-	for addr := range localAccounts {
-		proof, err := c.GetProof(ctx, *expectedHeader.Number, addr, []string{})
-		if err != nil {
-			fmt.Printf("⚠️  Could not get proof for account %s: %v\n", addr.Hex(), err)
-			continue
-		}
-
-		proofNonce, _ := new(big.Int).SetString(trimHexPrefix(proof.Nonce), 16)
-		proofBalance, _ := new(big.Int).SetString(trimHexPrefix(proof.Balance), 16)
-		proofCodeHash := common.HexToHash(proof.CodeHash)
-		proofStorageHash := common.HexToHash(proof.StorageHash)
-
-		localNonce := statedb.GetNonce(addr)
-		localBalance := statedb.GetBalance(addr)
-		localCodeHash := statedb.GetCodeHash(addr)
-		localStorageRoot := statedb.GetStorageRoot(addr)
-
-		accountExistsInProof := proofNonce.Uint64() > 0 || proofBalance.Cmp(big.NewInt(0)) > 0 || proofCodeHash != common.Hash{}
-		accountExistsLocally := localNonce > 0 || localBalance.Cmp(uint256.MustFromBig(big.NewInt(0))) > 0 || localCodeHash != common.Hash{}
-
-		if accountExistsInProof != accountExistsLocally {
-			fmt.Printf("❌ Account existence mismatch for %s: local exists=%t, proof exists=%t\n",
-				addr.Hex(), accountExistsLocally, accountExistsInProof)
-			continue
-		}
-
-		if !accountExistsInProof {
-			continue
-		}
-
-		if localNonce != proofNonce.Uint64() {
-			fmt.Printf("❌ Nonce mismatch for account %s: local %d, proof %d\n",
-				addr.Hex(), localNonce, proofNonce.Uint64())
-		}
-
-		if localBalance.Cmp(uint256.MustFromBig(proofBalance)) != 0 {
-			fmt.Printf("❌ Balance mismatch for account %s: local %s, proof %s\n",
-				addr.Hex(), localBalance.String(), proofBalance.String())
-		}
-
-		if localCodeHash != proofCodeHash {
-			fmt.Printf("❌ Code hash mismatch for account %s: local %s, proof %s\n",
-				addr.Hex(), localCodeHash.Hex(), proofCodeHash.Hex())
-		}
-
-		if localStorageRoot != proofStorageHash {
-			fmt.Printf("❌ Storage root mismatch for account %s: local %s, proof %s\n",
-				addr.Hex(), localStorageRoot.Hex(), proofStorageHash.Hex())
-		}
-	}
-
-	knownAccounts := []common.Address{
-		common.HexToAddress("0xa4b05fffffffffffffffffffffffffffffffffff"),
-		common.HexToAddress("0x00000000000000000000000000000000000A4B05"),
-		common.HexToAddress("0xA4b000000000000000000073657175656e636572"),
-	}
-
-	for _, addr := range knownAccounts {
-		if !localAccounts[addr] {
-			proof, err := c.GetProof(ctx, *expectedHeader.Number, addr, []string{})
-			if err == nil {
-				proofNonce, _ := new(big.Int).SetString(trimHexPrefix(proof.Nonce), 16)
-				proofBalance, _ := new(big.Int).SetString(trimHexPrefix(proof.Balance), 16)
-				proofCodeHash := common.HexToHash(proof.CodeHash)
-
-				if proofNonce.Uint64() > 0 || proofBalance.Cmp(big.NewInt(0)) > 0 || proofCodeHash != (common.Hash{}) {
-					fmt.Printf("❌ Missing account in local state: %s (exists in expected block)\n", addr.Hex())
-				}
-			}
-		}
-	}
-
-	localRoot := statedb.IntermediateRoot(false)
-	if localRoot != expectedHeader.Root {
-		fmt.Printf("❌ State root mismatch: local %s, expected %s\n",
-			localRoot.Hex(), expectedHeader.Root.Hex())
-	} else {
-		fmt.Printf("✅ State root matches!\n")
-	}
-
-	return nil
-}
-
 func (c *ArbitrumClient) getAllPossibleArbOSStorageKeys() []string {
 	computeStorageKey := func(parentKey []byte, id []byte) []byte {
 		return crypto.Keccak256(parentKey, id)
@@ -0,0 +1,174 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	rollupcore "github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/rollup-core"
+)
+
+// Values of the AssertionStatus enum of the rollup core contract.
+const (
+	AssertionStatusNoAssertion uint8 = iota
+	AssertionStatusPending
+	AssertionStatusConfirmed
+)
+
+var ErrAssertionLink = errors.New("assertion does not link to its child")
+var ErrAssertionCreationNotFound = errors.New("assertion creation not found")
+
+var assertionCreatedTopic = common.HexToHash("0x901c3aee23cf4478825462caaab375c606ab83516060388344f0650340753630")
+
+// HistoricalAssertion is a confirmed assertion reached by walking back from
+// the latest confirmed one.
+type HistoricalAssertion struct {
+	Hash           common.Hash                            `json:"hash"`
+	ParentHash     common.Hash                            `json:"parentAssertionHash"`
+	BlockHash      common.Hash                            `json:"blockHash"`
+	SendRoot       common.Hash                            `json:"sendRoot"`
+	Batch          uint64                                 `json:"batch"`
+	PosInBatch     uint64                                 `json:"posInBatch"`
+	CreatedAtBlock uint64                                 `json:"createdAtBlock"`
+	Created        *rollupcore.RollupCoreAssertionCreated `json:"-"`
+}
+
+// GetAssertionCreated fetches the AssertionCreated event of hash from the
+// parent chain block the assertion node was created in and checks that it
+// hashes to hash.
+func (ec *EthereumClient) GetAssertionCreated(ctx context.Context, hash common.Hash) (*rollupcore.RollupCoreAssertionCreated, *rollupcore.RollupCoreAssertionNode, error) {
+	node, err := ec.GetAssertionDetails(ctx, hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get assertion details: %w", err)
+	}
+	if node.Status == AssertionStatusNoAssertion {
+		return nil, nil, fmt.Errorf("%w: assertion %s does not exist", ErrAssertionCreationNotFound, hash.Hex())
+	}
+
+	block := new(big.Int).SetUint64(node.CreatedAtBlock)
+	logs, err := ec.provider.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: block,
+		ToBlock:   block,
+		Addresses: []common.Address{ec.contractAddr},
+		Topics:    [][]common.Hash{{assertionCreatedTopic}, {hash}},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(logs) == 0 {
+		return nil, nil, fmt.Errorf("%w: no AssertionCreated for %s in block %d", ErrAssertionCreationNotFound, hash.Hex(), node.CreatedAtBlock)
+	}
+	created, err := ec.rollupCore.ParseAssertionCreated(logs[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	generatedHash, err := GenerateAssertionHash(created.ParentAssertionHash, created.Assertion.AfterState, created.AfterInboxBatchAcc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate assertion hash: %w", err)
+	}
+	if generatedHash != hash {
+		return nil, nil, fmt.Errorf("mismatched assertion hash: expected %x, got %x", hash, generatedHash)
+	}
+	return created, node, nil
+}
+
+// AssertionHistory walks back from the assertion created by latest through
+// ParentAssertionHash and returns up to n of its ancestors, newest first. The
+// walk stops early at the genesis assertion and at the first assertion whose
+// creation cannot be found; any other error is returned.
+func (ec *EthereumClient) AssertionHistory(ctx context.Context, latest *rollupcore.RollupCoreAssertionCreated, n int) ([]*HistoricalAssertion, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("number of assertions must not be negative, got %d", n)
+	}
+	history := make([]*HistoricalAssertion, 0, n)
+	child := latest
+	for len(history) < n {
+		parentHash := common.Hash(child.ParentAssertionHash)
+		if parentHash == (common.Hash{}) {
+			break
+		}
+		created, node, err := ec.GetAssertionCreated(ctx, parentHash)
+		if errors.Is(err, ErrAssertionCreationNotFound) {
+			log.Debug("Stopped assertion history walk", "hash", parentHash, "err", err)
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk back to assertion %s: %w", parentHash.Hex(), err)
+		}
+		if err := checkAssertionLink(created, child); err != nil {
+			return nil, err
+		}
+		if node.Status != AssertionStatusConfirmed {
+			return nil, fmt.Errorf("%w: ancestor %s is not confirmed", ErrAssertionLink, parentHash.Hex())
+		}
+
+		state := GlobalState(created.Assertion.AfterState.GlobalState)
+		history = append(history, &HistoricalAssertion{
+			Hash:           parentHash,
+			ParentHash:     created.ParentAssertionHash,
+			BlockHash:      state.BlockHash(),
+			SendRoot:       state.SendRoot(),
+			Batch:          state.Batch(),
+			PosInBatch:     state.PosInBatch(),
+			CreatedAtBlock: created.Raw.BlockNumber,
+			Created:        created,
+		})
+		child = created
+	}
+	return history, nil
+}
+
+// checkAssertionLink checks that child was built on top of parent: it must
+// start from the state parent ended in, with the parent's parent and inbox
+// accumulator as its before state data.
+func checkAssertionLink(parent *rollupcore.RollupCoreAssertionCreated, child *rollupcore.RollupCoreAssertionCreated) error {
+	parentState, err := HashAssertionState(parent.Assertion.AfterState)
+	if err != nil {
+		return err
+	}
+	childBefore, err := HashAssertionState(child.Assertion.BeforeState)
+	if err != nil {
+		return err
+	}
+	switch {
+	case parentState != childBefore:
+		return fmt.Errorf("%w: %x does not start from the after state of %x", ErrAssertionLink, child.AssertionHash, parent.AssertionHash)
+	case child.Assertion.BeforeStateData.PrevPrevAssertionHash != parent.ParentAssertionHash:
+		return fmt.Errorf("%w: %x has the wrong grandparent", ErrAssertionLink, child.AssertionHash)
+	case child.Assertion.BeforeStateData.SequencerBatchAcc != parent.AfterInboxBatchAcc:
+		return fmt.Errorf("%w: %x has the wrong inbox accumulator", ErrAssertionLink, child.AssertionHash)
+	}
+	return nil
+}
+
+// AssertionHistory returns up to n confirmed assertions preceding the latest
+// confirmed one, newest first.
+func (lc *LightClient) AssertionHistory(ctx context.Context, n int) ([]*HistoricalAssertion, error) {
+	latest, err := lc.LatestConfirmedAssertion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return lc.ethClient.AssertionHistory(ctx, latest.Created, n)
+}
+
+// HistoricalHeader fetches the child chain block committed to by entry from
+// the first prover that serves it correctly, so verification can be anchored
+// to it, for instance with NewHeaderChain.
+func (lc *LightClient) HistoricalHeader(ctx context.Context, entry *HistoricalAssertion) (*types.Header, error) {
+	var errs []error
+	for _, prover := range lc.provers {
+		header, err := prover.verifiedHeader(ctx, entry.BlockHash)
+		if err == nil {
+			return header, nil
+		}
+		errs = append(errs, fmt.Errorf("prover %s: %w", prover.URL(), err))
+	}
+	return nil, fmt.Errorf("%w: block %s: %w", ErrNoHonestProver, entry.BlockHash.Hex(), errors.Join(errs...))
+}
@@ -0,0 +1,145 @@
+package lightclient
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/zeroheavy"
+)
+
+// BatchBuilder assembles sequencer batches in the format ParseSequencerMessage
+// decodes, so test cases can be written without a recorded batch.
+type BatchBuilder struct {
+	MinTimestamp         uint64
+	MaxTimestamp         uint64
+	MinL1Block           uint64
+	MaxL1Block           uint64
+	AfterDelayedMessages uint64
+
+	segments [][]byte
+	err      error
+}
+
+func NewBatchBuilder() *BatchBuilder {
+	return &BatchBuilder{}
+}
+
+// AddL2Message appends an uncompressed L2 message segment.
+func (b *BatchBuilder) AddL2Message(msg []byte) *BatchBuilder {
+	return b.AddSegment(arbstate.BatchSegmentKindL2Message, msg)
+}
+
+// AddL2MessageBrotli appends an L2 message segment compressed on its own.
+func (b *BatchBuilder) AddL2MessageBrotli(msg []byte) *BatchBuilder {
+	compressed, err := arbcompress.CompressWell(msg)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.AddSegment(arbstate.BatchSegmentKindL2MessageBrotli, compressed)
+}
+
+// AddDelayedMessage appends a segment reading the next delayed message.
+func (b *BatchBuilder) AddDelayedMessage() *BatchBuilder {
+	return b.AddSegment(arbstate.BatchSegmentKindDelayedMessages, nil)
+}
+
+func (b *BatchBuilder) AdvanceTimestamp(delta uint64) *BatchBuilder {
+	return b.addAdvance(arbstate.BatchSegmentKindAdvanceTimestamp, delta)
+}
+
+func (b *BatchBuilder) AdvanceL1BlockNumber(delta uint64) *BatchBuilder {
+	return b.addAdvance(arbstate.BatchSegmentKindAdvanceL1BlockNumber, delta)
+}
+
+func (b *BatchBuilder) addAdvance(kind uint8, delta uint64) *BatchBuilder {
+	encoded, err := rlp.EncodeToBytes(delta)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.AddSegment(kind, encoded)
+}
+
+// AddSegment appends a segment of any kind, including unknown ones, as is.
+func (b *BatchBuilder) AddSegment(kind uint8, payload []byte) *BatchBuilder {
+	segment := make([]byte, 0, 1+len(payload))
+	segment = append(segment, kind)
+	segment = append(segment, payload...)
+	b.segments = append(b.segments, segment)
+	return b
+}
+
+func (b *BatchBuilder) header() []byte {
+	header := make([]byte, 40)
+	binary.BigEndian.PutUint64(header[0:8], b.MinTimestamp)
+	binary.BigEndian.PutUint64(header[8:16], b.MaxTimestamp)
+	binary.BigEndian.PutUint64(header[16:24], b.MinL1Block)
+	binary.BigEndian.PutUint64(header[24:32], b.MaxL1Block)
+	binary.BigEndian.PutUint64(header[32:40], b.AfterDelayedMessages)
+	return header
+}
+
+// Segments returns the RLP encoded segment stream before compression.
+func (b *BatchBuilder) Segments() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	var stream []byte
+	for _, segment := range b.segments {
+		encoded, err := rlp.EncodeToBytes(segment)
+		if err != nil {
+			return nil, err
+		}
+		stream = append(stream, encoded...)
+	}
+	return stream, nil
+}
+
+func (b *BatchBuilder) brotliPayload() ([]byte, error) {
+	stream, err := b.Segments()
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := arbcompress.CompressWell(stream)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{daprovider.BrotliMessageHeaderByte}, compressed...), nil
+}
+
+// Build returns the batch with a brotli compressed payload, as posted by the
+// batch poster in calldata or blobs.
+func (b *BatchBuilder) Build() ([]byte, error) {
+	payload, err := b.brotliPayload()
+	if err != nil {
+		return nil, err
+	}
+	return append(b.header(), payload...), nil
+}
+
+// BuildZeroheavy returns the batch with the brotli payload additionally
+// zeroheavy encoded.
+func (b *BatchBuilder) BuildZeroheavy() ([]byte, error) {
+	payload, err := b.brotliPayload()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := io.ReadAll(zeroheavy.NewZeroheavyEncoder(bytes.NewReader(payload)))
+	if err != nil {
+		return nil, err
+	}
+	batch := append(b.header(), daprovider.ZeroheavyMessageHeaderByte)
+	return append(batch, encoded...), nil
+}
+
+// BuildRaw returns the batch with payload appended to the header unchanged,
+// for malformed or unsupported payloads.
+func (b *BatchBuilder) BuildRaw(payload []byte) []byte {
+	return append(b.header(), payload...)
+}
@@ -0,0 +1,124 @@
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	flag "github.com/spf13/pflag"
+)
+
+type BatchCacheConfig struct {
+	Size    int  `koanf:"size"`
+	Persist bool `koanf:"persist"`
+}
+
+var DefaultBatchCacheConfig = BatchCacheConfig{
+	Size:    64,
+	Persist: true,
+}
+
+func BatchCacheConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".size", DefaultBatchCacheConfig.Size, "number of decoded sequencer batches kept in memory (0 disables the cache)")
+	f.Bool(prefix+".persist", DefaultBatchCacheConfig.Persist, "also keep decoded batches in the store, if one is configured")
+}
+
+func (c *BatchCacheConfig) Validate() error {
+	if c.Size < 0 {
+		return fmt.Errorf("size: must not be negative, got %d", c.Size)
+	}
+	return nil
+}
+
+// CachedBatch is a sequencer batch decoded and verified by StartBatchHandler.
+// Batches live on the parent chain, so a cached batch is valid for every
+// prover that points at the same submission. The batch read the delayed
+// messages DelayedStart up to, but not including, DelayedEnd.
+type CachedBatch struct {
+	SequenceNumber uint64                          `json:"sequenceNumber"`
+	SubmissionTx   common.Hash                     `json:"submissionTx"`
+	BlockHash      common.Hash                     `json:"blockHash"`
+	DelayedStart   uint64                          `json:"delayedStart"`
+	DelayedEnd     uint64                          `json:"delayedEnd"`
+	Messages       []*arbostypes.L1IncomingMessage `json:"messages"`
+}
+
+// BatchCache keeps decoded batches by sequence number, with an index from the
+// submission transaction to the sequence number, since that is what provers
+// report. If a store is given, batches are also written to and read from it.
+type BatchCache struct {
+	batches     *lru.Cache[uint64, *CachedBatch]
+	submissions *lru.Cache[common.Hash, uint64]
+	store       *Store
+}
+
+// NewBatchCache returns nil if config disables the cache. store may be nil.
+func NewBatchCache(config *BatchCacheConfig, store *Store) (*BatchCache, error) {
+	if config.Size == 0 {
+		return nil, nil
+	}
+	batches, err := lru.New[uint64, *CachedBatch](config.Size)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := lru.New[common.Hash, uint64](config.Size)
+	if err != nil {
+		return nil, err
+	}
+	cache := &BatchCache{batches: batches, submissions: submissions}
+	if config.Persist {
+		cache.store = store
+	}
+	return cache, nil
+}
+
+// Get returns the batch with sequence number seqNum.
+func (c *BatchCache) Get(seqNum uint64) (*CachedBatch, bool) {
+	if batch, ok := c.batches.Get(seqNum); ok {
+		return batch, true
+	}
+	if c.store == nil {
+		return nil, false
+	}
+	batch, err := c.store.Batch(seqNum)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Warn("Failed to load stored batch", "seqNum", seqNum, "err", err)
+		}
+		return nil, false
+	}
+	c.batches.Add(seqNum, batch)
+	c.submissions.Add(batch.SubmissionTx, seqNum)
+	return batch, true
+}
+
+// BySubmission returns the batch posted in the parent chain transaction txHash.
+func (c *BatchCache) BySubmission(txHash common.Hash) (*CachedBatch, bool) {
+	if seqNum, ok := c.submissions.Get(txHash); ok {
+		return c.Get(seqNum)
+	}
+	if c.store == nil {
+		return nil, false
+	}
+	seqNum, err := c.store.BatchBySubmission(txHash)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Warn("Failed to look stored batch up", "tx", txHash, "err", err)
+		}
+		return nil, false
+	}
+	return c.Get(seqNum)
+}
+
+func (c *BatchCache) Add(batch *CachedBatch) {
+	c.batches.Add(batch.SequenceNumber, batch)
+	c.submissions.Add(batch.SubmissionTx, batch.SequenceNumber)
+	if c.store != nil {
+		if err := c.store.PutBatch(batch); err != nil {
+			log.Warn("Failed to store batch", "seqNum", batch.SequenceNumber, "err", err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const defaultCodeCacheSize = 4096
+
+var ErrCodeHashMismatch = errors.New("code does not hash to the proven code hash")
+
+// CodeCache keeps verified bytecode by its keccak256 hash. Since entries are
+// addressed by content, one cache can be shared by all provers and blocks.
+type CodeCache struct {
+	codes *lru.Cache[common.Hash, []byte]
+}
+
+func NewCodeCache(size int) (*CodeCache, error) {
+	codes, err := lru.New[common.Hash, []byte](size)
+	if err != nil {
+		return nil, err
+	}
+	return &CodeCache{codes: codes}, nil
+}
+
+func (c *CodeCache) Get(codeHash common.Hash) ([]byte, bool) {
+	return c.codes.Get(codeHash)
+}
+
+// Add stores code under its hash.
+func (c *CodeCache) Add(code []byte) common.Hash {
+	codeHash := crypto.Keccak256Hash(code)
+	c.codes.Add(codeHash, code)
+	return codeHash
+}
+
+// GetCode returns the code of addr at header, verified against the code hash
+// proven for the account in header.Root.
+func (c *ArbitrumClient) GetCode(ctx context.Context, addr common.Address, header *types.Header) ([]byte, error) {
+	account, err := c.VerifyAccount(ctx, addr, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	return c.codeByHash(ctx, addr, account.CodeHash, header.Number)
+}
+
+// codeByHash returns the code of addr, which must hash to codeHash, from the
+// cache or else from the prover at blockNumber.
+func (c *ArbitrumClient) codeByHash(ctx context.Context, addr common.Address, codeHash common.Hash, blockNumber *big.Int) ([]byte, error) {
+	if codeHash == types.EmptyCodeHash || codeHash == (common.Hash{}) {
+		return nil, nil
+	}
+	if code, ok := c.codeCache.Get(codeHash); ok {
+		return code, nil
+	}
+
+	code, err := c.ethClient.CodeAt(ctx, addr, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code for account %s: %w", addr, err)
+	}
+	if crypto.Keccak256Hash(code) != codeHash {
+		return nil, &StateProofError{Prover: c.url, Account: addr, Err: fmt.Errorf("%w %s", ErrCodeHashMismatch, codeHash.Hex())}
+	}
+	c.codeCache.Add(code)
+	return code, nil
+}
@@ -0,0 +1,303 @@
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	flag "github.com/spf13/pflag"
+)
+
+type Config struct {
+	Provers     []string          `koanf:"provers"`
+	ParentChain ParentChainConfig `koanf:"parent-chain"`
+	Chain       ChainConfig       `koanf:"chain"`
+	Trust       TrustConfig       `koanf:"trust"`
+	Timeouts    TimeoutsConfig    `koanf:"timeouts"`
+	Measurement MeasurementConfig `koanf:"measurement"`
+	Store       StoreConfig       `koanf:"store"`
+	Follow      FollowConfig      `koanf:"follow"`
+	BatchCache  BatchCacheConfig  `koanf:"batch-cache"`
+
+	DataAvailability DataAvailabilityConfig `koanf:"data-availability"`
+
+	// Transport carries the JSON-RPC requests to the parent chain and the
+	// provers instead of http.DefaultTransport, for instance to record or
+	// replay them with the replay package. It needs HTTP endpoints. Beacon
+	// requests of the blob client and DAS REST requests always go through
+	// http.DefaultTransport, since nitro's clients do not take a transport.
+	Transport http.RoundTripper `koanf:"-" json:"-"`
+}
+
+type ParentChainConfig struct {
+	URL               string        `koanf:"url"`
+	FallbackURLs      []string      `koanf:"fallback-urls"`
+	BeaconURL         string        `koanf:"beacon-url"`
+	Retries           int           `koanf:"retries"`
+	RetryBackoff      time.Duration `koanf:"retry-backoff"`
+	MaxRetryBackoff   time.Duration `koanf:"max-retry-backoff"`
+	RequestsPerSecond float64       `koanf:"requests-per-second"`
+	MaxLogRange       uint64        `koanf:"max-log-range"`
+}
+
+type ChainConfig struct {
+	ID                uint64 `koanf:"id"`
+	RollupCoreAddress string `koanf:"rollup-core-address"`
+	InfoFile          string `koanf:"info-file"`
+}
+
+type TrustConfig struct {
+	MinAgreeingProvers int    `koanf:"min-agreeing-provers"`
+	ChallengeWindow    uint64 `koanf:"challenge-window"`
+}
+
+type TimeoutsConfig struct {
+	Dial       time.Duration `koanf:"dial"`
+	Oracle     time.Duration `koanf:"oracle"`
+	Tournament time.Duration `koanf:"tournament"`
+}
+
+var DefaultParentChainConfig = ParentChainConfig{
+	URL:               "",
+	FallbackURLs:      []string{},
+	BeaconURL:         "",
+	Retries:           3,
+	RetryBackoff:      500 * time.Millisecond,
+	MaxRetryBackoff:   10 * time.Second,
+	RequestsPerSecond: 0,
+	MaxLogRange:       1500,
+}
+
+var DefaultChainConfig = ChainConfig{
+	ID:                0,
+	RollupCoreAddress: "",
+	InfoFile:          "",
+}
+
+var DefaultTrustConfig = TrustConfig{
+	MinAgreeingProvers: 1,
+	ChallengeWindow:    10,
+}
+
+var DefaultTimeoutsConfig = TimeoutsConfig{
+	Dial:       30 * time.Second,
+	Oracle:     10 * time.Minute,
+	Tournament: 0,
+}
+
+var DefaultConfig = Config{
+	Provers:     []string{},
+	ParentChain: DefaultParentChainConfig,
+	Chain:       DefaultChainConfig,
+	Trust:       DefaultTrustConfig,
+	Timeouts:    DefaultTimeoutsConfig,
+	Measurement: DefaultMeasurementConfig,
+	Store:       DefaultStoreConfig,
+	Follow:      DefaultFollowConfig,
+	BatchCache:  DefaultBatchCacheConfig,
+
+	DataAvailability: DefaultDataAvailabilityConfig,
+}
+
+func ConfigAddOptions(f *flag.FlagSet) {
+	f.StringSlice("provers", DefaultConfig.Provers, "RPC URLs of the child chain provers")
+	ParentChainConfigAddOptions("parent-chain", f)
+	ChainConfigAddOptions("chain", f)
+	TrustConfigAddOptions("trust", f)
+	TimeoutsConfigAddOptions("timeouts", f)
+	MeasurementConfigAddOptions("measurement", f)
+	StoreConfigAddOptions("store", f)
+	FollowConfigAddOptions("follow", f)
+	BatchCacheConfigAddOptions("batch-cache", f)
+	DataAvailabilityConfigAddOptions("data-availability", f)
+}
+
+func ParentChainConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".url", DefaultParentChainConfig.URL, "parent chain execution RPC URL")
+	f.StringSlice(prefix+".fallback-urls", DefaultParentChainConfig.FallbackURLs, "parent chain execution RPC URLs to fail over to, in order (HTTP only)")
+	f.String(prefix+".beacon-url", DefaultParentChainConfig.BeaconURL, "parent chain beacon RPC URL used for fetching blobs")
+	f.Int(prefix+".retries", DefaultParentChainConfig.Retries, "number of times a failed parent chain HTTP request is retried, moving to the next endpoint each time")
+	f.Duration(prefix+".retry-backoff", DefaultParentChainConfig.RetryBackoff, "delay before the first retry, doubled on every further retry")
+	f.Duration(prefix+".max-retry-backoff", DefaultParentChainConfig.MaxRetryBackoff, "upper bound on the delay between retries")
+	f.Float64(prefix+".requests-per-second", DefaultParentChainConfig.RequestsPerSecond, "maximum rate of parent chain HTTP requests (0 disables)")
+	f.Uint64(prefix+".max-log-range", DefaultParentChainConfig.MaxLogRange, "maximum number of blocks queried for logs at once, lowered automatically when a provider rejects the range")
+}
+
+func ChainConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Uint64(prefix+".id", DefaultChainConfig.ID, "child chain ID")
+	f.String(prefix+".rollup-core-address", DefaultChainConfig.RollupCoreAddress, "address of the rollup core contract on the parent chain")
+	f.String(prefix+".info-file", DefaultChainConfig.InfoFile, "additional chain info file to look the child chain up in")
+}
+
+func TrustConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Int(prefix+".min-agreeing-provers", DefaultTrustConfig.MinAgreeingProvers, "minimum number of provers that must serve the confirmed block for it to be used as the anchor")
+	f.Uint64(prefix+".challenge-window", DefaultTrustConfig.ChallengeWindow, "number of blocks past the common prefix the oracles re-check when two provers agree")
+}
+
+func TimeoutsConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Duration(prefix+".dial", DefaultTimeoutsConfig.Dial, "timeout for connecting to the parent chain and the provers")
+	f.Duration(prefix+".oracle", DefaultTimeoutsConfig.Oracle, "timeout for running both oracles on a single block (0 disables)")
+	f.Duration(prefix+".tournament", DefaultTimeoutsConfig.Tournament, "timeout for a whole tournament (0 disables)")
+}
+
+func (c *Config) Validate() error {
+	var errs []error
+
+	if len(c.Provers) == 0 {
+		errs = append(errs, errors.New("provers: at least one prover is required"))
+	}
+	for i, prover := range c.Provers {
+		if err := validateURL(prover); err != nil {
+			errs = append(errs, fmt.Errorf("provers[%d]: %w", i, err))
+		}
+	}
+	if err := c.ParentChain.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("parent-chain: %w", err))
+	}
+	if err := c.Chain.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("chain: %w", err))
+	}
+	if err := c.Trust.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("trust: %w", err))
+	}
+	if c.Trust.MinAgreeingProvers > len(c.Provers) {
+		errs = append(errs, fmt.Errorf("trust.min-agreeing-provers: %d exceeds the %d configured provers", c.Trust.MinAgreeingProvers, len(c.Provers)))
+	}
+	if err := c.Timeouts.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("timeouts: %w", err))
+	}
+	if err := c.Measurement.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("measurement: %w", err))
+	}
+	if err := c.Follow.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("follow: %w", err))
+	}
+	if err := c.BatchCache.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("batch-cache: %w", err))
+	}
+	if err := c.DataAvailability.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("data-availability: %w", err))
+	}
+	if c.Transport != nil {
+		for i, prover := range c.Provers {
+			if !isHTTPURL(prover) {
+				errs = append(errs, fmt.Errorf("provers[%d]: %w", i, ErrTransportNeedsHTTP))
+			}
+		}
+		if !isHTTPURL(c.ParentChain.URL) {
+			errs = append(errs, fmt.Errorf("parent-chain.url: %w", ErrTransportNeedsHTTP))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c *ParentChainConfig) Validate() error {
+	if err := validateURL(c.URL); err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	for i, fallback := range c.FallbackURLs {
+		if err := validateURL(fallback); err != nil {
+			return fmt.Errorf("fallback-urls[%d]: %w", i, err)
+		}
+	}
+	if len(c.FallbackURLs) > 0 {
+		for _, endpoint := range c.Endpoints() {
+			if !isHTTPURL(endpoint) {
+				return fmt.Errorf("fallback-urls: failover needs HTTP endpoints, got %q", endpoint)
+			}
+		}
+	}
+	if c.BeaconURL != "" {
+		if err := validateURL(c.BeaconURL); err != nil {
+			return fmt.Errorf("beacon-url: %w", err)
+		}
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("retries: must not be negative, got %d", c.Retries)
+	}
+	if c.RetryBackoff < 0 {
+		return fmt.Errorf("retry-backoff: must not be negative, got %s", c.RetryBackoff)
+	}
+	if c.MaxRetryBackoff < c.RetryBackoff {
+		return fmt.Errorf("max-retry-backoff: %s is below retry-backoff %s", c.MaxRetryBackoff, c.RetryBackoff)
+	}
+	if c.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests-per-second: must not be negative, got %v", c.RequestsPerSecond)
+	}
+	if c.MaxLogRange == 0 {
+		return errors.New("max-log-range: must be at least 1")
+	}
+	return nil
+}
+
+// Endpoints returns the primary URL followed by the fallbacks.
+func (c *ParentChainConfig) Endpoints() []string {
+	return append([]string{c.URL}, c.FallbackURLs...)
+}
+
+func (c *ChainConfig) Validate() error {
+	if c.ID == 0 {
+		return errors.New("id: must be set")
+	}
+	if !common.IsHexAddress(c.RollupCoreAddress) {
+		return fmt.Errorf("rollup-core-address: %q is not a hex address", c.RollupCoreAddress)
+	}
+	return nil
+}
+
+func (c *TrustConfig) Validate() error {
+	if c.MinAgreeingProvers < 1 {
+		return fmt.Errorf("min-agreeing-provers: must be at least 1, got %d", c.MinAgreeingProvers)
+	}
+	if c.ChallengeWindow == 0 {
+		return errors.New("challenge-window: must be at least 1")
+	}
+	return nil
+}
+
+func (c *TimeoutsConfig) Validate() error {
+	if c.Dial <= 0 {
+		return fmt.Errorf("dial: must be positive, got %s", c.Dial)
+	}
+	if c.Oracle < 0 {
+		return fmt.Errorf("oracle: must not be negative, got %s", c.Oracle)
+	}
+	if c.Tournament < 0 {
+		return fmt.Errorf("tournament: must not be negative, got %s", c.Tournament)
+	}
+	return nil
+}
+
+// RollupCore returns the parsed rollup core address. The config must have
+// been validated.
+func (c *ChainConfig) RollupCore() common.Address {
+	return common.HexToAddress(c.RollupCoreAddress)
+}
+
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+func validateURL(raw string) error {
+	if raw == "" {
+		return errors.New("must be set")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("unsupported URL scheme in %q", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", raw)
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-package main
+package lightclient
 
 import (
 	"context"
@@ -9,7 +9,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
@@ -26,14 +25,6 @@ type BatchHandlerType struct {
 	ChainInfoFile         string                        `koanf:"chain-info-file"`
 }
 
-type DasHandlerType struct {
-	ParentChainNodeURL    string                        `koanf:"parent-chain-node-url"`
-	BatchSubmissionTxHash string                        `koanf:"parent-chain-submission-tx-hash"`
-	ChildChainId          uint64                        `koanf:"child-chain-id"`
-	BlobClient            headerreader.BlobClientConfig `koanf:"blob-client"`
-	ChainInfoFile         string                        `koanf:"chain-info-file"`
-}
-
 const defaultChainInfoFile = "./nitro/cmd/chaininfo/arbitrum_chain_info.json"
 
 func getSha256(msg []byte) string {
@@ -42,9 +33,19 @@ func getSha256(msg []byte) string {
 	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
 }
 
-func ExecuteConsensusOracle(ctx context.Context, prevL1Data MessageTrackingL1Data, currL1Data MessageTrackingL1Data, parentChainURL string, childChainId uint64, beaconRPCURL string) (bool, error) {
+// rollupAddresses looks the parent chain contracts of the child chain up in
+// the chain info files.
+func rollupAddresses(config *Config) (chaininfo.RollupAddresses, error) {
+	chainInfoFiles := []string{defaultChainInfoFile}
+	if config.Chain.InfoFile != "" {
+		chainInfoFiles = append(chainInfoFiles, config.Chain.InfoFile)
+	}
+	return chaininfo.GetRollupAddressesConfig(config.Chain.ID, "", chainInfoFiles, "")
+}
+
+func ExecuteConsensusOracle(ctx context.Context, prevL1Data MessageTrackingL1Data, currL1Data MessageTrackingL1Data, clientConfig *Config, parentChain *ParentChainClient, dasReader *DASReader, cache *BatchCache) (bool, error) {
 	if prevL1Data.Message.Header.Kind == arbostypes.L1MessageType_Initialize {
-		messages, _, err := StartBatchHandler(ctx, prevL1Data, parentChainURL, childChainId, beaconRPCURL)
+		messages, _, err := StartBatchHandler(ctx, prevL1Data, clientConfig, parentChain, dasReader, cache)
 		if err != nil {
 			return false, err
 		}
@@ -53,7 +54,7 @@ func ExecuteConsensusOracle(ctx context.Context, prevL1Data MessageTrackingL1Dat
 	}
 
 	if prevL1Data.L1TxHash == currL1Data.L1TxHash {
-		messages, _, err := StartBatchHandler(ctx, prevL1Data, parentChainURL, childChainId, beaconRPCURL)
+		messages, _, err := StartBatchHandler(ctx, prevL1Data, clientConfig, parentChain, dasReader, cache)
 		if err != nil {
 			return false, err
 		}
@@ -67,11 +68,11 @@ func ExecuteConsensusOracle(ctx context.Context, prevL1Data MessageTrackingL1Dat
 	}
 
 	// if the tx hash is different, we need to get the messages from two batches
-	messages1, targetBatchNum1, err := StartBatchHandler(ctx, prevL1Data, parentChainURL, childChainId, beaconRPCURL)
+	messages1, targetBatchNum1, err := StartBatchHandler(ctx, prevL1Data, clientConfig, parentChain, dasReader, cache)
 	if err != nil {
 		return false, err
 	}
-	messages2, targetBatchNum2, err := StartBatchHandler(ctx, currL1Data, parentChainURL, childChainId, beaconRPCURL)
+	messages2, targetBatchNum2, err := StartBatchHandler(ctx, currL1Data, clientConfig, parentChain, dasReader, cache)
 
 	if err != nil {
 		return false, err
@@ -91,31 +92,38 @@ func ExecuteConsensusOracle(ctx context.Context, prevL1Data MessageTrackingL1Dat
 	}
 }
 
-func StartBatchHandler(ctx context.Context, L1Data MessageTrackingL1Data, parentChainURL string, childChainId uint64, beaconRPCURL string) ([]*arbostypes.L1IncomingMessage, uint64, error) {
+func StartBatchHandler(ctx context.Context, L1Data MessageTrackingL1Data, clientConfig *Config, parentChain *ParentChainClient, dasReader *DASReader, cache *BatchCache) ([]*arbostypes.L1IncomingMessage, uint64, error) {
+	if cache != nil {
+		if cached, ok := cache.BySubmission(L1Data.L1TxHash); ok {
+			return cached.Messages, cached.SequenceNumber, nil
+		}
+	}
+
 	txHash := L1Data.L1TxHash.Hex()
 
 	config := &BatchHandlerType{
-		ParentChainNodeURL:    parentChainURL,
+		ParentChainNodeURL:    clientConfig.ParentChain.URL,
 		BatchSubmissionTxHash: txHash,
-		ChildChainId:          childChainId,
+		ChildChainId:          clientConfig.Chain.ID,
 		BlobClient: headerreader.BlobClientConfig{
-			BeaconUrl: beaconRPCURL,
+			BeaconUrl: clientConfig.ParentChain.BeaconURL,
 		},
-		ChainInfoFile: defaultChainInfoFile,
+		ChainInfoFile: clientConfig.Chain.InfoFile,
 	}
 
-	chainInfoFiles := []string{defaultChainInfoFile}
-	if config.ChainInfoFile != "" {
-		chainInfoFiles = append(chainInfoFiles, config.ChainInfoFile)
-	}
-
-	chainConfig, err := chaininfo.GetRollupAddressesConfig(config.ChildChainId, "", chainInfoFiles, "")
+	chainConfig, err := rollupAddresses(clientConfig)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	var parentChainClient *ethclient.Client
-	parentChainClient, _ = ethclient.DialContext(ctx, config.ParentChainNodeURL)
+	if parentChain == nil {
+		parentChain, err = NewParentChainClient(ctx, &clientConfig.ParentChain, clientConfig.Transport)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to connect to the parent chain: %w", err)
+		}
+		defer parentChain.Close()
+	}
+	parentChainClient := parentChain.Client()
 
 	submissionTxReceipt, err := parentChainClient.TransactionReceipt(ctx, common.HexToHash(config.BatchSubmissionTxHash))
 
@@ -182,9 +190,16 @@ func StartBatchHandler(ctx context.Context, L1Data MessageTrackingL1Data, parent
 		return batchData, nil
 	}
 
-	lastBatchDelayedCount, err := getAfterDelayedBySeqNum(int64(batch.SequenceNumber)-1, seqFilter)
+	// The first batch starts from an empty delayed inbox
+	var lastBatchDelayedCount uint64
+	if batch.SequenceNumber > 0 {
+		lastBatchDelayedCount, err = getAfterDelayedBySeqNum(int64(batch.SequenceNumber)-1, seqFilter)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get delayed messages read before batch %d: %w", batch.SequenceNumber, err)
+		}
+	}
 
-	err = setDelayedToBackendByIndexRange(ctx, parentChainClient, chainConfig.SequencerInbox, chainConfig.Bridge, int64(lastBatchDelayedCount), int64(batch.AfterDelayedCount)-1, backend)
+	err = setDelayedToBackendByIndexRange(ctx, parentChainClient, chainConfig.SequencerInbox, chainConfig.Bridge, int64(lastBatchDelayedCount), int64(batch.AfterDelayedCount)-1, batch.AfterDelayedAcc, backend)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get delayed msg: %w", err)
 	}
@@ -196,14 +211,26 @@ func StartBatchHandler(ctx context.Context, L1Data MessageTrackingL1Data, parent
 	}
 
 	blobClient, err := headerreader.NewBlobClient(config.BlobClient, parentChainClient)
-	blobClient.Initialize(ctx)
 	if err != nil {
-		fmt.Println("failed to initialize blob client", "err", err)
-		return nil, 0, err
+		return nil, 0, fmt.Errorf("failed to create blob client: %w", err)
+	}
+	if err := blobClient.Initialize(ctx); err != nil {
+		return nil, 0, fmt.Errorf("failed to initialize blob client: %w", err)
+	}
+
+	if dasReader == nil && clientConfig.DataAvailability.Enable {
+		dasReader, err = NewDASReader(ctx, &clientConfig.DataAvailability, parentChain, chainConfig.SequencerInbox)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer dasReader.Close()
 	}
 
 	var dapReaders []daprovider.Reader
 
+	if dasReader != nil {
+		dapReaders = append(dapReaders, dasReader)
+	}
 	dapReaders = append(dapReaders, daprovider.NewReaderForBlobReader(blobClient))
 
 	bytes, batchBlockHash, err := backend.PeekSequencerInbox()
@@ -212,6 +239,10 @@ func StartBatchHandler(ctx context.Context, L1Data MessageTrackingL1Data, parent
 		return nil, 0, err
 	}
 
+	if err := verifyBatch(ctx, parentChainClient, chainConfig.Bridge, batch, bytes); err != nil {
+		return nil, 0, err
+	}
+
 	parsedSequencerMsg, err := ParseSequencerMessage(ctx, backend.batchSeqNum, batchBlockHash, bytes, dapReaders, daprovider.KeysetPanicIfInvalid)
 
 	if err != nil {
@@ -224,5 +255,16 @@ func StartBatchHandler(ctx context.Context, L1Data MessageTrackingL1Data, parent
 		return nil, 0, err
 	}
 
+	if cache != nil {
+		cache.Add(&CachedBatch{
+			SequenceNumber: targetBatchNum,
+			SubmissionTx:   L1Data.L1TxHash,
+			BlockHash:      batchBlockHash,
+			DelayedStart:   lastBatchDelayedCount,
+			DelayedEnd:     batch.AfterDelayedCount,
+			Messages:       messages,
+		})
+	}
+
 	return messages, targetBatchNum, nil
 }
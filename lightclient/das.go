@@ -0,0 +1,105 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/das"
+	flag "github.com/spf13/pflag"
+)
+
+var ErrNoDASReader = errors.New("batch is stored with a data availability committee but no DAS reader is configured")
+
+type DataAvailabilityConfig struct {
+	Enable         bool          `koanf:"enable"`
+	RestURLs       []string      `koanf:"rest-urls"`
+	OnlineURLList  string        `koanf:"online-url-list"`
+	RequestTimeout time.Duration `koanf:"request-timeout"`
+}
+
+var DefaultDataAvailabilityConfig = DataAvailabilityConfig{
+	Enable:         false,
+	RestURLs:       []string{},
+	OnlineURLList:  "",
+	RequestTimeout: 5 * time.Second,
+}
+
+func DataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultDataAvailabilityConfig.Enable, "read AnyTrust batches from the data availability committee")
+	f.StringSlice(prefix+".rest-urls", DefaultDataAvailabilityConfig.RestURLs, "REST aggregator URLs to fetch batch data from, e.g. a local DAS server")
+	f.String(prefix+".online-url-list", DefaultDataAvailabilityConfig.OnlineURLList, "URL of a list of REST aggregator URLs, fetched periodically")
+	f.Duration(prefix+".request-timeout", DefaultDataAvailabilityConfig.RequestTimeout, "timeout for fetching a single batch from the REST aggregators")
+}
+
+func (c *DataAvailabilityConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if len(c.RestURLs) == 0 && c.OnlineURLList == "" {
+		return errors.New("rest-urls: at least one REST aggregator or an online-url-list is required")
+	}
+	for i, restURL := range c.RestURLs {
+		if !isHTTPURL(restURL) {
+			return fmt.Errorf("rest-urls[%d]: %q is not an HTTP URL", i, restURL)
+		}
+	}
+	if c.OnlineURLList != "" && !isHTTPURL(c.OnlineURLList) {
+		return fmt.Errorf("online-url-list: %q is not an HTTP URL", c.OnlineURLList)
+	}
+	if c.RequestTimeout <= 0 {
+		return fmt.Errorf("request-timeout: must be positive, got %s", c.RequestTimeout)
+	}
+	return nil
+}
+
+// DASReader recovers AnyTrust batches from REST aggregators. The aggregators
+// are not trusted: nitro's reader checks the data against the hash in the
+// batch certificate, the certificate against the BLS signatures of the
+// committee, and the committee against the keyset hash the sequencer inbox
+// has marked valid, which is read from the parent chain.
+type DASReader struct {
+	daprovider.Reader
+	lifecycle *das.LifecycleManager
+}
+
+// NewDASReader returns nil if config disables the data availability
+// committee.
+func NewDASReader(ctx context.Context, config *DataAvailabilityConfig, parentChain *ParentChainClient, sequencerInbox common.Address) (*DASReader, error) {
+	if !config.Enable {
+		return nil, nil
+	}
+
+	dasConfig := das.DefaultDataAvailabilityConfig
+	dasConfig.Enable = true
+	dasConfig.RequestTimeout = config.RequestTimeout
+	dasConfig.RestAggregator = das.DefaultRestfulClientAggregatorConfig
+	dasConfig.RestAggregator.Enable = true
+	dasConfig.RestAggregator.Urls = config.RestURLs
+	dasConfig.RestAggregator.OnlineUrlList = config.OnlineURLList
+
+	restReader, lifecycle, err := das.CreateDAReaderForNode(ctx, &dasConfig, nil, &sequencerInbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create REST aggregator reader: %w", err)
+	}
+	keysetFetcher, err := das.NewKeysetFetcher(parentChain.Client(), sequencerInbox)
+	if err != nil {
+		lifecycle.StopAndWaitUntil(time.Second)
+		return nil, fmt.Errorf("failed to create keyset fetcher: %w", err)
+	}
+
+	reader := das.NewReaderTimeoutWrapper(restReader, config.RequestTimeout)
+	return &DASReader{
+		Reader:    daprovider.NewReaderForDAS(reader, keysetFetcher),
+		lifecycle: lifecycle,
+	}, nil
+}
+
+func (r *DASReader) Close() {
+	if r.lifecycle != nil {
+		r.lifecycle.StopAndWaitUntil(time.Second)
+	}
+}
@@ -1,10 +1,9 @@
-package main
+package lightclient
 
 import (
 	"bytes"
 	"context"
 	"fmt"
-	"math/big"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -14,32 +13,35 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"golang.org/x/crypto/sha3"
 
-	rollupcore "github.com/jakovmitrovski/arbitrum-light-client-go/cmd/client/rollup-core"
+	rollupcore "github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/rollup-core"
 )
 
 type EthereumClient struct {
+	parentChain  *ParentChainClient
 	provider     *ethclient.Client
 	rollupCore   *rollupcore.RollupCore
 	contractAddr common.Address
 }
 
-// NewEthereumClient connects to the chain and initializes the RollupCore binding
-func NewEthereumClient(rpcURL string, contractAddr common.Address) (*EthereumClient, error) {
-	client, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		return nil, err
-	}
-	core, err := rollupcore.NewRollupCore(contractAddr, client)
+// NewEthereumClient initializes the RollupCore binding on top of the shared
+// parent chain connection. Closing the client closes the connection.
+func NewEthereumClient(parentChain *ParentChainClient, contractAddr common.Address) (*EthereumClient, error) {
+	core, err := rollupcore.NewRollupCore(contractAddr, parentChain.Client())
 	if err != nil {
 		return nil, err
 	}
 	return &EthereumClient{
-		provider:     client,
+		parentChain:  parentChain,
+		provider:     parentChain.Client(),
 		rollupCore:   core,
 		contractAddr: contractAddr,
 	}, nil
 }
 
+func (ec *EthereumClient) Close() {
+	ec.parentChain.Close()
+}
+
 // GetLatestAssertion fetches the latest confirmed assertion hash
 func (ec *EthereumClient) GetLatestAssertion(ctx context.Context) ([32]byte, error) {
 	return ec.rollupCore.LatestConfirmed(&bind.CallOpts{Context: ctx})
@@ -54,7 +56,8 @@ func (ec *EthereumClient) GetAssertionDetails(ctx context.Context, hash [32]byte
 	return &result, nil
 }
 
-// Generic internal helper to get a log by topic
+// Generic internal helper to get a log by topic, searching back from the
+// latest block
 func (ec *EthereumClient) getAssertionLog(ctx context.Context, topic common.Hash, assertionHash common.Hash) (*types.Log, error) {
 	// Get latest block
 	latestBlock, err := ec.provider.BlockNumber(ctx)
@@ -62,59 +65,41 @@ func (ec *EthereumClient) getAssertionLog(ctx context.Context, topic common.Hash
 		return nil, err
 	}
 
-	var log *types.Log
-
-	for log == nil {
-		query := ethereum.FilterQuery{
-			FromBlock: big.NewInt(int64(latestBlock - 1499)),
-			ToBlock:   big.NewInt(int64(latestBlock)),
-			Addresses: []common.Address{ec.contractAddr},
-			Topics: [][]common.Hash{
-				{topic},
-				{assertionHash}, // indexed topic1
-			},
-		}
-
-		logs, err := ec.provider.FilterLogs(ctx, query)
-		if err != nil {
-			latestBlock -= 1499
-			continue
-		}
-		if len(logs) == 0 {
-			latestBlock -= 1499
-			continue
-		}
-
-		return &logs[0], nil
-
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{ec.contractAddr},
+		Topics: [][]common.Hash{
+			{topic},
+			{assertionHash}, // indexed topic1
+		},
 	}
-	return nil, fmt.Errorf("no log found for topic %s", topic.Hex())
-}
 
-func (ec *EthereumClient) GetAssertionConfirmedLog(ctx context.Context) (*rollupcore.RollupCoreAssertionConfirmed, error) {
-	// 1. Get latest assertion hash
-	hash, err := ec.GetLatestAssertion(ctx)
+	logs, err := ec.parentChain.LatestLogs(ctx, query, 0, latestBlock)
 	if err != nil {
 		return nil, err
 	}
+	if len(logs) == 0 {
+		return nil, fmt.Errorf("no log found for topic %s", topic.Hex())
+	}
+	return &logs[0], nil
+}
 
-	// 2. Fetch log
+// GetAssertionConfirmedLog fetches the AssertionConfirmed event of the
+// assertion hash
+func (ec *EthereumClient) GetAssertionConfirmedLog(ctx context.Context, hash [32]byte) (*rollupcore.RollupCoreAssertionConfirmed, error) {
+	// 1. Fetch log
 	log, err := ec.getAssertionLog(ctx, common.HexToHash("0xfc42829b29c259a7370ab56c8f69fce23b5f351a9ce151da453281993ec0090c"), hash)
 	if err != nil {
 		return nil, err
 	}
 
-	// 3. Parse it
+	// 2. Parse it
 	return ec.rollupCore.ParseAssertionConfirmed(*log)
 }
 
-func (ec *EthereumClient) GetAssertionCreatedLog(ctx context.Context) (*rollupcore.RollupCoreAssertionCreated, error) {
-	hash, err := ec.GetLatestAssertion(ctx)
-	if err != nil {
-		return nil, err
-	}
-
-	log, err := ec.getAssertionLog(ctx, common.HexToHash("0x901c3aee23cf4478825462caaab375c606ab83516060388344f0650340753630"), hash)
+// GetAssertionCreatedLog fetches and validates the AssertionCreated event of
+// the assertion hash
+func (ec *EthereumClient) GetAssertionCreatedLog(ctx context.Context, hash [32]byte) (*rollupcore.RollupCoreAssertionCreated, error) {
+	log, err := ec.getAssertionLog(ctx, assertionCreatedTopic, hash)
 	if err != nil {
 		return nil, err
 	}
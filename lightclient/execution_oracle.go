@@ -1,11 +1,12 @@
 // Copyright 2021-2022, Offchain Labs, Inc.
 // For license information, see https://github.com/OffchainLabs/nitro/blob/master/LICENSE.md
 
-package main
+package lightclient
 
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -14,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/triedb"
 
@@ -38,7 +40,8 @@ func (c *SimpleChainContext) GetHeader(hash common.Hash, number uint64) *types.H
 	ctx := context.Background()
 	block, err := c.client.GetBlockByHash(ctx, hash)
 	if err != nil {
-		panic(err)
+		log.Error("failed to get header for chain context", "hash", hash, "number", number, "err", err)
+		return nil
 	}
 	return block.Header()
 }
@@ -47,7 +50,11 @@ func (c *SimpleChainContext) Config() *params.ChainConfig {
 	return c.chainConfig
 }
 
-func ExecuteExecutionOracle(ctx context.Context, arbClient *ArbitrumClient, lastBlockHeader *types.Header, message *arbostypes.L1IncomingMessage, expected_block_header *types.Header, chainId uint64, extraMessages ...*arbostypes.L1IncomingMessage) bool {
+var ErrExecutionMismatch = errors.New("re-executed block does not match expected header")
+
+// ExecuteExecutionOracle re-executes message on top of lastBlockHeader and
+// checks the produced block against expected_block_header.
+func ExecuteExecutionOracle(ctx context.Context, arbClient *ArbitrumClient, lastBlockHeader *types.Header, message *arbostypes.L1IncomingMessage, expected_block_header *types.Header, chainId uint64, extraMessages ...*arbostypes.L1IncomingMessage) (bool, error) {
 	if message.Header.Kind == arbostypes.L1MessageType_Initialize {
 		return handleInitializeMessage(arbClient, message, expected_block_header, extraMessages)
 	} else {
@@ -55,7 +62,7 @@ func ExecuteExecutionOracle(ctx context.Context, arbClient *ArbitrumClient, last
 	}
 }
 
-func handleInitializeMessage(arbClient *ArbitrumClient, message *arbostypes.L1IncomingMessage, expected_block_header *types.Header, extraMessages []*arbostypes.L1IncomingMessage) bool {
+func handleInitializeMessage(arbClient *ArbitrumClient, message *arbostypes.L1IncomingMessage, expected_block_header *types.Header, extraMessages []*arbostypes.L1IncomingMessage) (bool, error) {
 	memdb := rawdb.NewMemoryDatabase()
 	trieDB := triedb.NewDatabase(memdb, nil)
 	trieDB.Commit(common.Hash{}, false)
@@ -63,52 +70,48 @@ func handleInitializeMessage(arbClient *ArbitrumClient, message *arbostypes.L1In
 
 	statedb, err := state.NewDeterministic(common.Hash{}, stateDB)
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("failed to create statedb: %w", err)
 	}
 
 	initMessage, err := message.ParseInitMessage()
 	if err != nil {
-		panic(err)
+		return false, fmt.Errorf("failed to parse init message: %w", err)
 	}
 	chainConfig := initMessage.ChainConfig
 	if chainConfig == nil {
-		fmt.Println("no chain config in the init message. Falling back to hardcoded chain config.")
+		log.Warn("no chain config in the init message, falling back to hardcoded chain config")
 		chainConfig, err = chaininfo.GetChainConfig(initMessage.ChainId, "", 0, []string{}, "")
 		if err != nil {
-			panic(err)
+			return false, fmt.Errorf("failed to get chain config: %w", err)
 		}
 	}
 
 	_, err = arbosState.InitializeArbosState(statedb, burn.NewSystemBurner(nil, false), chainConfig, initMessage)
 	if err != nil {
-		panic(fmt.Sprintf("Error initializing ArbOS: %v", err.Error()))
+		return false, fmt.Errorf("error initializing ArbOS: %w", err)
 	}
 
 	newBlock := arbosState.MakeGenesisBlock(common.Hash{}, chainConfig.ArbitrumChainParams.GenesisBlockNum, 0, statedb.IntermediateRoot(true), chainConfig)
-	receipts := types.Receipts{}
 
 	chainContext := &SimpleChainContext{chainConfig: chainConfig, client: arbClient}
 
-	for i, extraMessage := range extraMessages {
-		newBlock, receipts, err = arbos.ProduceBlock(extraMessage, 0, newBlock.Header(), statedb, chainContext, false, core.MessageReplayMode)
+	for _, extraMessage := range extraMessages {
+		newBlock, _, err = arbos.ProduceBlock(extraMessage, 0, newBlock.Header(), statedb, chainContext, false, core.MessageReplayMode)
 		if err != nil {
-			panic(fmt.Sprintf("Error producing block: %v", err.Error()))
-		}
-
-		if i == len(extraMessages)-1 {
-			for _, receipt := range receipts {
-				fmt.Println("receipt", receipt.TxHash, receipt.Status)
-			}
+			return false, fmt.Errorf("error producing block: %w", err)
 		}
 	}
 
-	return validateBlockHeaders(newBlock.Header(), expected_block_header)
+	if err := validateBlockHeaders(newBlock.Header(), expected_block_header); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func handleNonInitializeMessage(ctx context.Context, arbClient *ArbitrumClient, lastBlockHeader *types.Header, message *arbostypes.L1IncomingMessage, expected_block_header *types.Header, chainId uint64) bool {
+func handleNonInitializeMessage(ctx context.Context, arbClient *ArbitrumClient, lastBlockHeader *types.Header, message *arbostypes.L1IncomingMessage, expected_block_header *types.Header, chainId uint64) (bool, error) {
 	statedb, _, _, err := arbClient.ReconstructStateFromProofsAndTrace(ctx, expected_block_header, lastBlockHeader, chainId)
 	if err != nil {
-		panic(fmt.Sprintf("Error opening state db: %v", err.Error()))
+		return false, fmt.Errorf("error opening state db: %w", err)
 	}
 
 	chainConfig := chaininfo.ArbitrumDevTestChainConfig()
@@ -118,47 +121,34 @@ func handleNonInitializeMessage(ctx context.Context, arbClient *ArbitrumClient,
 
 	newBlock, _, err := arbos.ProduceBlock(message, 0, lastBlockHeader, statedb, chainContext, false, core.MessageReplayMode)
 	if err != nil {
-		fmt.Printf("Failed to produce block: %v\n", err)
-		return false
+		return false, fmt.Errorf("failed to produce block: %w", err)
 	}
 
-	return validateBlockHeaders(newBlock.Header(), expected_block_header)
+	if err := validateBlockHeaders(newBlock.Header(), expected_block_header); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func validateBlockHeaders(actual *types.Header, expected *types.Header) bool {
+func validateBlockHeaders(actual *types.Header, expected *types.Header) error {
 	if actual.ReceiptHash != expected.ReceiptHash {
-		fmt.Println("receipt hash equality failed")
-		fmt.Println("actual.ReceiptHash", actual.ReceiptHash.Hex())
-		fmt.Println("expected.ReceiptHash", expected.ReceiptHash.Hex())
-		return false
+		return fmt.Errorf("%w: receipt hash %s, expected %s", ErrExecutionMismatch, actual.ReceiptHash.Hex(), expected.ReceiptHash.Hex())
 	}
 
 	if actual.TxHash != expected.TxHash {
-		fmt.Println("tx hash equality failed")
-		fmt.Println("actual.TxHash", actual.TxHash.Hex())
-		fmt.Println("expected.TxHash", expected.TxHash.Hex())
-		return false
+		return fmt.Errorf("%w: tx hash %s, expected %s", ErrExecutionMismatch, actual.TxHash.Hex(), expected.TxHash.Hex())
 	}
 
 	if actual.MixDigest != expected.MixDigest {
-		fmt.Println("mix digest equality failed")
-		fmt.Println("actual.MixDigest", actual.MixDigest.Hex())
-		fmt.Println("expected.MixDigest", expected.MixDigest.Hex())
-		return false
+		return fmt.Errorf("%w: mix digest %s, expected %s", ErrExecutionMismatch, actual.MixDigest.Hex(), expected.MixDigest.Hex())
 	}
 
 	if !bytes.Equal(actual.Extra, expected.Extra) {
-		fmt.Println("extra equality failed")
-		fmt.Println("actual.Extra", actual.Extra)
-		fmt.Println("expected.Extra", expected.Extra)
-		return false
+		return fmt.Errorf("%w: extra %x, expected %x", ErrExecutionMismatch, actual.Extra, expected.Extra)
 	}
 
 	if actual.Root != expected.Root {
-		fmt.Println("root equality failed")
-		fmt.Println("actual.Root", actual.Root.Hex())
-		fmt.Println("expected.Root", expected.Root.Hex())
-		return false
+		return fmt.Errorf("%w: root %s, expected %s", ErrExecutionMismatch, actual.Root.Hex(), expected.Root.Hex())
 	}
 
 	if actual.Nonce != expected.Nonce {
@@ -166,11 +156,8 @@ func validateBlockHeaders(actual *types.Header, expected *types.Header) bool {
 	}
 
 	if actual.Hash() != expected.Hash() {
-		fmt.Println("hash equality failed")
-		fmt.Println("actual.Hash", actual.Hash().Hex())
-		fmt.Println("expected.Hash", expected.Hash().Hex())
-		return false
+		return fmt.Errorf("%w: hash %s, expected %s", ErrExecutionMismatch, actual.Hash().Hex(), expected.Hash().Hex())
 	}
 
-	return true
+	return nil
 }
@@ -0,0 +1,111 @@
+// Package fakeprover serves recorded JSON-RPC responses, so the client can run
+// against a prover, or a parent chain node, without network access.
+package fakeprover
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Fixture is a recorded JSON-RPC call. Params left empty match any params of
+// the method. Error, if set, is returned instead of Result.
+type Fixture struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *FixtureError   `json:"error,omitempty"`
+}
+
+type FixtureError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// LoadFile reads a JSON array of fixtures.
+func LoadFile(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %w", path, err)
+	}
+	return fixtures, nil
+}
+
+// LoadDir reads every .json file of dir in lexical order.
+func LoadDir(dir string) ([]Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var fixtures []Fixture
+	for _, path := range paths {
+		loaded, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, loaded...)
+	}
+	return fixtures, nil
+}
+
+// WriteFile writes fixtures as an indented JSON array.
+func WriteFile(path string, fixtures []Fixture) error {
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// CanonicalParams normalizes params so that requests differing only in key
+// order, whitespace or hex letter case match the same fixture.
+func CanonicalParams(params json.RawMessage) (string, error) {
+	if len(bytes.TrimSpace(params)) == 0 {
+		return "", nil
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(params, &decoded); err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(lowerHex(decoded))
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func lowerHex(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			return strings.ToLower(v)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = lowerHex(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = lowerHex(v[key])
+		}
+		return v
+	default:
+		return v
+	}
+}
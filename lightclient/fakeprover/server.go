@@ -0,0 +1,170 @@
+package fakeprover
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+const (
+	errCodeParse          = -32700
+	errCodeMethodNotFound = -32601
+)
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *FixtureError   `json:"error,omitempty"`
+}
+
+// Server answers JSON-RPC requests from fixtures. A call recorded several
+// times is answered with the recorded responses in order, repeating the last
+// one once they run out.
+type Server struct {
+	mu        sync.Mutex
+	exact     map[string][]Fixture
+	anyParams map[string][]Fixture
+	served    map[string]int
+	unmatched []string
+}
+
+func New(fixtures []Fixture) (*Server, error) {
+	s := &Server{
+		exact:     make(map[string][]Fixture),
+		anyParams: make(map[string][]Fixture),
+		served:    make(map[string]int),
+	}
+	for _, fixture := range fixtures {
+		params, err := CanonicalParams(fixture.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid params in fixture for %s: %w", fixture.Method, err)
+		}
+		if params == "" {
+			s.anyParams[fixture.Method] = append(s.anyParams[fixture.Method], fixture)
+		} else {
+			key := fixture.Method + params
+			s.exact[key] = append(s.exact[key], fixture)
+		}
+	}
+	return s, nil
+}
+
+// NewFromDir creates a server from the fixture files in dir.
+func NewFromDir(dir string) (*Server, error) {
+	fixtures, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return New(fixtures)
+}
+
+// Start serves s on a local port until the returned server is closed. Its URL
+// field is the endpoint to pass to the client.
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// Unmatched returns the calls no fixture was found for, as method and
+// canonical params.
+func (s *Server) Unmatched() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.unmatched...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var requests []request
+		if err := json.Unmarshal(body, &requests); err != nil {
+			json.NewEncoder(w).Encode(parseError(err))
+			return
+		}
+		responses := make([]*response, len(requests))
+		for i := range requests {
+			responses[i] = s.handle(&requests[i])
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		json.NewEncoder(w).Encode(parseError(err))
+		return
+	}
+	json.NewEncoder(w).Encode(s.handle(&req))
+}
+
+func parseError(err error) *response {
+	return &response{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage("null"),
+		Error:   &FixtureError{Code: errCodeParse, Message: err.Error()},
+	}
+}
+
+func (s *Server) handle(req *request) *response {
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+
+	params, err := CanonicalParams(req.Params)
+	if err != nil {
+		resp.Error = &FixtureError{Code: errCodeParse, Message: err.Error()}
+		return resp
+	}
+
+	fixture, ok := s.lookup(req.Method, params)
+	if !ok {
+		resp.Error = &FixtureError{Code: errCodeMethodNotFound, Message: fmt.Sprintf("no fixture for %s %s", req.Method, params)}
+		return resp
+	}
+	if fixture.Error != nil {
+		resp.Error = fixture.Error
+		return resp
+	}
+	resp.Result = fixture.Result
+	if len(resp.Result) == 0 {
+		resp.Result = json.RawMessage("null")
+	}
+	return resp
+}
+
+func (s *Server) lookup(method string, params string) (Fixture, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := method + params
+	fixtures, ok := s.exact[key]
+	if !ok {
+		key = method
+		fixtures, ok = s.anyParams[method]
+	}
+	if !ok {
+		s.unmatched = append(s.unmatched, method+" "+params)
+		return Fixture{}, false
+	}
+
+	i := s.served[key]
+	if i < len(fixtures)-1 {
+		s.served[key] = i + 1
+	}
+	return fixtures[min(i, len(fixtures)-1)], true
+}
@@ -0,0 +1,148 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	flag "github.com/spf13/pflag"
+
+	rollupcore "github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/rollup-core"
+)
+
+type FollowConfig struct {
+	PollInterval time.Duration `koanf:"poll-interval"`
+	Subscribe    bool          `koanf:"subscribe"`
+}
+
+var DefaultFollowConfig = FollowConfig{
+	PollInterval: time.Minute,
+	Subscribe:    true,
+}
+
+func FollowConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Duration(prefix+".poll-interval", DefaultFollowConfig.PollInterval, "how often LatestConfirmed is polled for a new assertion")
+	f.Bool(prefix+".subscribe", DefaultFollowConfig.Subscribe, "also watch AssertionConfirmed events, if the parent chain endpoint supports subscriptions")
+}
+
+func (c *FollowConfig) Validate() error {
+	if c.PollInterval <= 0 {
+		return errors.New("poll-interval must be positive")
+	}
+	return nil
+}
+
+// Follow keeps the head verified as assertions get confirmed. Whenever the
+// latest confirmed assertion changes, the anchor is moved to it, the provers
+// are re-checked against it and the tournament is played again from the new
+// anchor. If from is not nil, following starts past the assertion it was
+// verified against. onHead is called with every new head; an error returned
+// from it stops following. Failed rounds are logged and retried on the next
+// poll.
+func (lc *LightClient) Follow(ctx context.Context, from *Head, onHead func(*Head) error) error {
+	trigger := make(chan struct{}, 1)
+	if lc.config.Follow.Subscribe {
+		sub, err := lc.watchConfirmations(ctx, trigger)
+		if err != nil {
+			log.Info("Not subscribing to AssertionConfirmed, polling only", "err", err)
+		} else {
+			defer sub()
+		}
+	}
+
+	ticker := time.NewTicker(lc.config.Follow.PollInterval)
+	defer ticker.Stop()
+
+	var current common.Hash
+	if from != nil {
+		current = from.Anchor.Assertion.Hash
+	}
+	for {
+		latestAssertion, err := lc.ethClient.GetLatestAssertion(ctx)
+		latest := common.Hash(latestAssertion)
+		if err != nil {
+			log.Warn("Failed to get latest assertion", "err", err)
+		} else if latest != current {
+			head, err := lc.advance(ctx, latest)
+			if err != nil {
+				log.Warn("Failed to verify head for new assertion", "assertion", latest, "err", err)
+			} else {
+				current = latest
+				if err := onHead(head); err != nil {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-trigger:
+		}
+	}
+}
+
+// advance moves the anchor to the confirmed assertion latest and plays the
+// tournament over the range past it.
+func (lc *LightClient) advance(ctx context.Context, latest common.Hash) (*Head, error) {
+	assertion, err := lc.LatestConfirmedAssertion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if assertion.Hash != latest {
+		return nil, fmt.Errorf("latest confirmed assertion moved to %s while fetching %s", assertion.Hash.Hex(), latest.Hex())
+	}
+
+	anchor, err := lc.VerifyAnchor(ctx, assertion)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Anchor moved", "assertion", assertion.Hash, "block", anchor.Header.Number, "hash", anchor.Header.Hash(), "provers", len(anchor.Provers))
+
+	result, err := lc.Tournament(ctx, anchor, 0)
+	if err != nil {
+		return nil, err
+	}
+	if result.Head() == nil {
+		return nil, fmt.Errorf("%w: no prover survived the tournament", ErrNoHonestProver)
+	}
+
+	return &Head{Anchor: anchor, Tournament: result}, nil
+}
+
+// watchConfirmations signals trigger on every AssertionConfirmed event. It
+// returns a function cancelling the subscription.
+func (lc *LightClient) watchConfirmations(ctx context.Context, trigger chan<- struct{}) (func(), error) {
+	sink := make(chan *rollupcore.RollupCoreAssertionConfirmed)
+	sub, err := lc.ethClient.rollupCore.WatchAssertionConfirmed(&bind.WatchOpts{Context: ctx}, sink, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case event := <-sink:
+				log.Debug("AssertionConfirmed", "assertion", common.Hash(event.AssertionHash), "block", event.Raw.BlockNumber)
+				select {
+				case trigger <- struct{}{}:
+				default:
+				}
+			case err := <-sub.Err():
+				if err != nil {
+					log.Warn("AssertionConfirmed subscription failed, polling only", "err", err)
+				}
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return sub.Unsubscribe, nil
+}
@@ -0,0 +1,62 @@
+package lightclient
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	rollupcore "github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/rollup-core"
+)
+
+var ErrAssertionStateMismatch = errors.New("AssertionConfirmed and AssertionCreated describe different states")
+
+// GlobalState is the machine global state of an assertion, with the packed
+// bytes32 and uint64 values of the contract struct exposed by name.
+type GlobalState rollupcore.RollupCoreGlobalState
+
+// BlockHash is the hash of the last child chain block of the assertion.
+func (s GlobalState) BlockHash() common.Hash {
+	return s.Bytes32Vals[0]
+}
+
+// SendRoot is the root of the outbox merkle tree after the last block.
+func (s GlobalState) SendRoot() common.Hash {
+	return s.Bytes32Vals[1]
+}
+
+// Batch is the sequencer inbox batch the next message is read from.
+func (s GlobalState) Batch() uint64 {
+	return s.U64Vals[0]
+}
+
+// PosInBatch is the position of the next message within Batch.
+func (s GlobalState) PosInBatch() uint64 {
+	return s.U64Vals[1]
+}
+
+func (s GlobalState) String() string {
+	return fmt.Sprintf("GlobalState{BlockHash: %s, SendRoot: %s, Batch: %d, PosInBatch: %d}", s.BlockHash().Hex(), s.SendRoot().Hex(), s.Batch(), s.PosInBatch())
+}
+
+// AfterState returns the global state the assertion committed to.
+func (a *ConfirmedAssertion) AfterState() GlobalState {
+	return GlobalState(a.Created.Assertion.AfterState.GlobalState)
+}
+
+// CheckConfirmedState checks that confirmed is the confirmation of created and
+// that the block hash and send root it reports are the ones in the after
+// state of created, whose hash ValidateAssertion already checked.
+func CheckConfirmedState(confirmed *rollupcore.RollupCoreAssertionConfirmed, created *rollupcore.RollupCoreAssertionCreated) error {
+	if confirmed.AssertionHash != created.AssertionHash {
+		return fmt.Errorf("%w: confirmed assertion %s, created assertion %s", ErrAssertionStateMismatch, common.Hash(confirmed.AssertionHash).Hex(), common.Hash(created.AssertionHash).Hex())
+	}
+	state := GlobalState(created.Assertion.AfterState.GlobalState)
+	if confirmed.BlockHash != state.BlockHash() {
+		return fmt.Errorf("%w: assertion %s: confirmed block hash %s, after state block hash %s", ErrAssertionStateMismatch, common.Hash(confirmed.AssertionHash).Hex(), common.Hash(confirmed.BlockHash).Hex(), state.BlockHash().Hex())
+	}
+	if confirmed.SendRoot != state.SendRoot() {
+		return fmt.Errorf("%w: assertion %s: confirmed send root %s, after state send root %s", ErrAssertionStateMismatch, common.Hash(confirmed.AssertionHash).Hex(), common.Hash(confirmed.SendRoot).Hex(), state.SendRoot().Hex())
+	}
+	return nil
+}
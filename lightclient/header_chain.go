@@ -0,0 +1,281 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrHeaderLink = errors.New("header does not link to its verified neighbour")
+var ErrOutOfRange = errors.New("block is outside the verified header range")
+
+const defaultHeaderBatchSize = 100
+
+// HeadersByNumber fetches the headers of blocks from to to, inclusive, in a
+// single batch request.
+func (c *ArbitrumClient) HeadersByNumber(ctx context.Context, from uint64, to uint64) ([]*types.Header, error) {
+	if to < from {
+		return nil, nil
+	}
+	headers := make([]*types.Header, to-from+1)
+	batch := make([]rpc.BatchElem, len(headers))
+	for i := range batch {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(from + uint64(i)), false},
+			Result: &headers[i],
+		}
+	}
+	if err := c.rpcClient.BatchCallContext(ctx, batch); err != nil {
+		return nil, err
+	}
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("header %d: %w", from+uint64(i), elem.Error)
+		}
+		if headers[i] == nil || headers[i].Number == nil || headers[i].Number.Uint64() != from+uint64(i) {
+			return nil, fmt.Errorf("header %d not found", from+uint64(i))
+		}
+	}
+	return headers, nil
+}
+
+// HeaderChain is a contiguous range of block hashes linked by ParentHash to a
+// trusted header. Walking back from the trusted header verifies every older
+// block. Walking forward only verifies blocks once the walk reaches another
+// trusted hash, since a prover can make up any number of children.
+//
+// If the chain has a store, headers up to the trusted one are written to it
+// and read back from it, so blocks verified in an earlier run are not fetched
+// again. Newer headers are only as good as the target the caller extended the
+// chain to, so they are not stored.
+type HeaderChain struct {
+	prover    *ArbitrumClient
+	batchSize uint64
+	store     *Store
+	trusted   uint64
+
+	mu      sync.RWMutex
+	lowest  uint64
+	highest uint64
+	hashes  map[uint64]common.Hash
+}
+
+// NewHeaderChain starts a chain at trusted, which must be verified by the
+// caller, for instance the anchor of a confirmed assertion. store may be nil.
+func NewHeaderChain(prover *ArbitrumClient, trusted *types.Header, batchSize uint64, store *Store) *HeaderChain {
+	if batchSize == 0 {
+		batchSize = defaultHeaderBatchSize
+	}
+	number := trusted.Number.Uint64()
+	return &HeaderChain{
+		prover:    prover,
+		batchSize: batchSize,
+		store:     store,
+		trusted:   number,
+		lowest:    number,
+		highest:   number,
+		hashes:    map[uint64]common.Hash{number: trusted.Hash()},
+	}
+}
+
+// Range returns the lowest and the highest verified block number.
+func (hc *HeaderChain) Range() (uint64, uint64) {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.lowest, hc.highest
+}
+
+// Hash returns the verified hash of block number.
+func (hc *HeaderChain) Hash(number uint64) (common.Hash, error) {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	hash, ok := hc.hashes[number]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("%w: %d not in [%d, %d]", ErrOutOfRange, number, hc.lowest, hc.highest)
+	}
+	return hash, nil
+}
+
+// Check returns an error unless hash is the verified hash of block number.
+func (hc *HeaderChain) Check(number uint64, hash common.Hash) error {
+	trusted, err := hc.Hash(number)
+	if err != nil {
+		return err
+	}
+	if trusted != hash {
+		return fmt.Errorf("%w: block %d is %s, verified %s", ErrHeaderLink, number, hash.Hex(), trusted.Hex())
+	}
+	return nil
+}
+
+// Header fetches the header of block number and checks it against the
+// verified hash.
+func (hc *HeaderChain) Header(ctx context.Context, number uint64) (*types.Header, error) {
+	hash, err := hc.Hash(number)
+	if err != nil {
+		return nil, err
+	}
+	if header := hc.storedHeader(hash); header != nil {
+		return header, nil
+	}
+	header, err := hc.prover.verifiedHeader(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	hc.persist([]*types.Header{header})
+	return header, nil
+}
+
+// ExtendBack verifies the blocks down to number by following ParentHash from
+// the lowest verified block.
+func (hc *HeaderChain) ExtendBack(ctx context.Context, number uint64) error {
+	lowest, _ := hc.Range()
+	if number >= lowest {
+		return nil
+	}
+	lowestHeader, err := hc.Header(ctx, lowest)
+	if err != nil {
+		return err
+	}
+	child := lowestHeader.ParentHash
+
+	for lowest > number {
+		if header := hc.storedHeader(child); header != nil {
+			lowest--
+			hc.add(map[uint64]common.Hash{lowest: child})
+			child = header.ParentHash
+			continue
+		}
+		from := number
+		if lowest-number > hc.batchSize {
+			from = lowest - hc.batchSize
+		}
+		headers, err := hc.prover.HeadersByNumber(ctx, from, lowest-1)
+		if err != nil {
+			return err
+		}
+		hashes := make(map[uint64]common.Hash, len(headers))
+		for i := len(headers) - 1; i >= 0; i-- {
+			hash := headers[i].Hash()
+			if hash != child {
+				return fmt.Errorf("%w: block %d hashes to %s, child expects %s", ErrHeaderLink, from+uint64(i), hash.Hex(), child.Hex())
+			}
+			hashes[from+uint64(i)] = hash
+			child = headers[i].ParentHash
+		}
+		hc.add(hashes)
+		hc.persist(headers)
+		lowest = from
+		log.Debug("Extended header chain back", "lowest", lowest)
+	}
+	return nil
+}
+
+// ExtendForward verifies the blocks up to the trusted block number with hash
+// target, following ParentHash from the highest verified block. Nothing is
+// added unless the walk ends at target.
+func (hc *HeaderChain) ExtendForward(ctx context.Context, number uint64, target common.Hash) error {
+	_, highest := hc.Range()
+	if number <= highest {
+		hash, err := hc.Hash(number)
+		if err != nil {
+			return err
+		}
+		if hash != target {
+			return fmt.Errorf("%w: block %d is %s, target %s", ErrHeaderLink, number, hash.Hex(), target.Hex())
+		}
+		return nil
+	}
+	parent, err := hc.Hash(highest)
+	if err != nil {
+		return err
+	}
+
+	hashes := make(map[uint64]common.Hash, number-highest)
+	for next := highest + 1; next <= number; {
+		to := min(number, next+hc.batchSize-1)
+		headers, err := hc.prover.HeadersByNumber(ctx, next, to)
+		if err != nil {
+			return err
+		}
+		for i, header := range headers {
+			if header.ParentHash != parent {
+				return fmt.Errorf("%w: block %d has parent %s, expected %s", ErrHeaderLink, next+uint64(i), header.ParentHash.Hex(), parent.Hex())
+			}
+			parent = header.Hash()
+			hashes[next+uint64(i)] = parent
+		}
+		next = to + 1
+	}
+	if parent != target {
+		return fmt.Errorf("%w: block %d hashes to %s, target %s", ErrHeaderLink, number, parent.Hex(), target.Hex())
+	}
+	hc.add(hashes)
+	log.Debug("Extended header chain forward", "highest", number)
+	return nil
+}
+
+func (hc *HeaderChain) add(hashes map[uint64]common.Hash) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	for number, hash := range hashes {
+		hc.hashes[number] = hash
+		hc.lowest = min(hc.lowest, number)
+		hc.highest = max(hc.highest, number)
+	}
+}
+
+// storedHeader returns the header with hash from the store, or nil if the
+// chain has no store or the header is not in it.
+func (hc *HeaderChain) storedHeader(hash common.Hash) *types.Header {
+	if hc.store == nil {
+		return nil
+	}
+	header, err := hc.store.HeaderByHash(hash)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Warn("Failed to load stored header", "hash", hash, "err", err)
+		}
+		return nil
+	}
+	return header
+}
+
+func (hc *HeaderChain) persist(headers []*types.Header) {
+	if hc.store == nil {
+		return
+	}
+	var trusted []*types.Header
+	for _, header := range headers {
+		if header.Number.Uint64() <= hc.trusted {
+			trusted = append(trusted, header)
+		}
+	}
+	if len(trusted) == 0 {
+		return
+	}
+	if err := hc.store.PutHeaders(trusted); err != nil {
+		log.Warn("Failed to store verified headers", "count", len(headers), "err", err)
+	}
+}
+
+// HeaderChain starts a header chain at the block of the confirmed assertion
+// head was played from, fetching headers from the tournament head. Older
+// blocks are verified with ExtendBack; newer ones only with ExtendForward to
+// a hash the caller trusts, such as the claim of the tournament head, which
+// is not confirmed on the parent chain.
+func (lc *LightClient) HeaderChain(head *Head) (*HeaderChain, error) {
+	survivor := head.Tournament.Head()
+	if survivor == nil {
+		return nil, ErrNoVerifiedHead
+	}
+	return NewHeaderChain(survivor.Prover, head.Anchor.Header, defaultHeaderBatchSize, lc.store), nil
+}
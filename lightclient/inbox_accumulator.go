@@ -0,0 +1,169 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+)
+
+var ErrInboxAccumulator = errors.New("sequencer inbox accumulator mismatch")
+var ErrDelayedAccumulator = errors.New("delayed inbox accumulator mismatch")
+
+// SequencerInboxAcc is the accumulator the bridge stores after a batch:
+// keccak256(beforeAcc, dataHash, delayedAcc).
+func SequencerInboxAcc(beforeAcc common.Hash, dataHash common.Hash, delayedAcc common.Hash) common.Hash {
+	return crypto.Keccak256Hash(beforeAcc.Bytes(), dataHash.Bytes(), delayedAcc.Bytes())
+}
+
+// VerifyBatchAccumulator checks that serialized, the sequencer message as
+// returned by SequencerInboxBatch.Serialize, is the data the batch's
+// accumulator commits to. The data hash is taken over the 40 byte header and
+// the payload, which for blob batches is the list of versioned hashes.
+func VerifyBatchAccumulator(batch *arbnode.SequencerInboxBatch, serialized []byte) error {
+	acc := SequencerInboxAcc(batch.BeforeInboxAcc, crypto.Keccak256Hash(serialized), batch.AfterDelayedAcc)
+	if acc != batch.AfterInboxAcc {
+		return fmt.Errorf("%w: batch %d data gives %s, event has %s", ErrInboxAccumulator, batch.SequenceNumber, acc.Hex(), batch.AfterInboxAcc.Hex())
+	}
+	return nil
+}
+
+// sequencerInboxAcc returns the accumulator the bridge stores after batch
+// seqNum.
+func sequencerInboxAcc(ctx context.Context, bridge *bridgegen.IBridgeCaller, seqNum uint64) (common.Hash, error) {
+	acc, err := bridge.SequencerInboxAccs(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(seqNum))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get sequencer inbox accumulator %d: %w", seqNum, err)
+	}
+	return acc, nil
+}
+
+// checkBatchAgainstBridge checks that the accumulators of batch link it to its
+// predecessor in the bridge, whose accumulators VerifyInboxAccumulator ties to
+// the confirmed assertion.
+func checkBatchAgainstBridge(ctx context.Context, bridge *bridgegen.IBridgeCaller, batch *arbnode.SequencerInboxBatch) error {
+	var beforeAcc common.Hash
+	if batch.SequenceNumber > 0 {
+		acc, err := sequencerInboxAcc(ctx, bridge, batch.SequenceNumber-1)
+		if err != nil {
+			return err
+		}
+		beforeAcc = acc
+	}
+	if batch.BeforeInboxAcc != beforeAcc {
+		return fmt.Errorf("%w: batch %d starts from %s, bridge has %s", ErrInboxAccumulator, batch.SequenceNumber, batch.BeforeInboxAcc.Hex(), beforeAcc.Hex())
+	}
+	afterAcc, err := sequencerInboxAcc(ctx, bridge, batch.SequenceNumber)
+	if err != nil {
+		return err
+	}
+	if batch.AfterInboxAcc != afterAcc {
+		return fmt.Errorf("%w: batch %d ends at %s, bridge has %s", ErrInboxAccumulator, batch.SequenceNumber, batch.AfterInboxAcc.Hex(), afterAcc.Hex())
+	}
+	return nil
+}
+
+// VerifyInboxAccumulator checks that the bridge's sequencer inbox accumulator
+// at the last batch read by assertion is the AfterInboxBatchAcc the assertion
+// committed to, so batches checked against the bridge are the ones the rollup
+// is executing.
+func (lc *LightClient) VerifyInboxAccumulator(ctx context.Context, assertion *ConfirmedAssertion) error {
+	batch := assertion.AfterState().Batch()
+	if batch == 0 {
+		return nil
+	}
+	addresses, err := rollupAddresses(lc.config)
+	if err != nil {
+		return err
+	}
+	bridge, err := bridgegen.NewIBridgeCaller(addresses.Bridge, lc.ethClient.provider)
+	if err != nil {
+		return err
+	}
+	acc, err := sequencerInboxAcc(ctx, bridge, batch-1)
+	if err != nil {
+		return err
+	}
+	if acc != assertion.Created.AfterInboxBatchAcc {
+		return fmt.Errorf("%w: bridge has %s after batch %d, assertion %s committed to %s", ErrInboxAccumulator, acc.Hex(), batch-1, assertion.Hash.Hex(), common.Hash(assertion.Created.AfterInboxBatchAcc).Hex())
+	}
+	return nil
+}
+
+func verifyBatch(ctx context.Context, client *ethclient.Client, bridgeAddress common.Address, batch *arbnode.SequencerInboxBatch, serialized []byte) error {
+	if err := VerifyBatchAccumulator(batch, serialized); err != nil {
+		return err
+	}
+	bridge, err := bridgegen.NewIBridgeCaller(bridgeAddress, client)
+	if err != nil {
+		return err
+	}
+	return checkBatchAgainstBridge(ctx, bridge, batch)
+}
+
+// delayedInboxAcc returns the accumulator the bridge stores after delayed
+// message seqNum.
+func delayedInboxAcc(ctx context.Context, bridge *bridgegen.IBridgeCaller, seqNum uint64) (common.Hash, error) {
+	acc, err := bridge.DelayedInboxAccs(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(seqNum))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get delayed inbox accumulator %d: %w", seqNum, err)
+	}
+	return acc, nil
+}
+
+// verifyDelayedMessages chains the delayed messages from to to into the
+// delayed accumulator with DelayedInboxMessage.AfterInboxAcc, the hashing the
+// node uses, starting from the bridge's accumulator before from. The result
+// must match both the bridge's accumulator after to and afterDelayedAcc, the
+// delayed accumulator of the batch reading them. Messages outside the range
+// are ignored.
+func verifyDelayedMessages(ctx context.Context, bridge *bridgegen.IBridgeCaller, all []*arbnode.DelayedInboxMessage, from uint64, to uint64, afterDelayedAcc common.Hash) error {
+	messages := make(map[uint64]*arbnode.DelayedInboxMessage, to-from+1)
+	for _, msg := range all {
+		seqNum, err := msg.Message.Header.SeqNum()
+		if err != nil {
+			return err
+		}
+		if seqNum >= from && seqNum <= to {
+			messages[seqNum] = msg
+		}
+	}
+
+	var acc common.Hash
+	if from > 0 {
+		var err error
+		acc, err = delayedInboxAcc(ctx, bridge, from-1)
+		if err != nil {
+			return err
+		}
+	}
+	for seqNum := from; seqNum <= to; seqNum++ {
+		msg, ok := messages[seqNum]
+		if !ok {
+			return fmt.Errorf("%w: delayed message %d is missing", ErrDelayedAccumulator, seqNum)
+		}
+		if msg.BeforeInboxAcc != acc {
+			return fmt.Errorf("%w: delayed message %d starts from %s, expected %s", ErrDelayedAccumulator, seqNum, msg.BeforeInboxAcc.Hex(), acc.Hex())
+		}
+		acc = msg.AfterInboxAcc()
+	}
+
+	bridgeAcc, err := delayedInboxAcc(ctx, bridge, to)
+	if err != nil {
+		return err
+	}
+	if acc != bridgeAcc {
+		return fmt.Errorf("%w: messages up to %d give %s, bridge has %s", ErrDelayedAccumulator, to, acc.Hex(), bridgeAcc.Hex())
+	}
+	if acc != afterDelayedAcc {
+		return fmt.Errorf("%w: messages up to %d give %s, batch has %s", ErrDelayedAccumulator, to, acc.Hex(), afterDelayedAcc.Hex())
+	}
+	return nil
+}
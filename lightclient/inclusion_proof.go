@@ -0,0 +1,91 @@
+package lightclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+var ErrInclusionProof = errors.New("inclusion proof does not verify")
+
+// InclusionProof proves the item at Index of an ordered trie, such as the
+// transactions or receipts trie of a block, against Root.
+type InclusionProof struct {
+	Root  common.Hash     `json:"root"`
+	Index uint64          `json:"index"`
+	Nodes []hexutil.Bytes `json:"nodes"`
+}
+
+// Verify checks that the proof commits to value at Index.
+func (p *InclusionProof) Verify(value []byte) error {
+	db, err := proofNodes(hexBytesToStrings(p.Nodes))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInclusionProof, err)
+	}
+	proven, err := trie.VerifyProof(p.Root, rlp.AppendUint64(nil, p.Index), db)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInclusionProof, err)
+	}
+	if !bytes.Equal(proven, value) {
+		return fmt.Errorf("%w: item %d differs from the proven one", ErrInclusionProof, p.Index)
+	}
+	return nil
+}
+
+// orderedTrie is the trie of a list keyed by the RLP encoded index, as used
+// for the transactions and receipts roots.
+type orderedTrie struct {
+	trie *trie.Trie
+	root common.Hash
+}
+
+func newOrderedTrie(list types.DerivableList) (*orderedTrie, error) {
+	t := trie.NewEmpty(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	var buf bytes.Buffer
+	for i := 0; i < list.Len(); i++ {
+		buf.Reset()
+		list.EncodeIndex(i, &buf)
+		if err := t.Update(rlp.AppendUint64(nil, uint64(i)), common.CopyBytes(buf.Bytes())); err != nil {
+			return nil, err
+		}
+	}
+	return &orderedTrie{trie: t, root: t.Hash()}, nil
+}
+
+func (t *orderedTrie) prove(index uint64) (*InclusionProof, error) {
+	collector := &proofCollector{}
+	if err := t.trie.Prove(rlp.AppendUint64(nil, index), collector); err != nil {
+		return nil, err
+	}
+	return &InclusionProof{Root: t.root, Index: index, Nodes: collector.nodes}, nil
+}
+
+// proofCollector gathers the nodes written by trie.Prove in order.
+type proofCollector struct {
+	nodes []hexutil.Bytes
+}
+
+func (c *proofCollector) Put(key []byte, value []byte) error {
+	c.nodes = append(c.nodes, common.CopyBytes(value))
+	return nil
+}
+
+func (c *proofCollector) Delete(key []byte) error {
+	return errors.New("not supported")
+}
+
+func hexBytesToStrings(nodes []hexutil.Bytes) []string {
+	strs := make([]string, len(nodes))
+	for i, node := range nodes {
+		strs[i] = node.String()
+	}
+	return strs
+}
@@ -0,0 +1,400 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	rollupcore "github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/rollup-core"
+)
+
+var ErrNoHonestProver = errors.New("not enough provers agree with the confirmed assertion")
+
+// LightClient ties together the parent chain view of the rollup and the set
+// of untrusted provers serving the child chain.
+type LightClient struct {
+	config    *Config
+	ethClient *EthereumClient
+	provers   []*ArbitrumClient
+	store     *Store
+	dasReader *DASReader
+}
+
+// ConfirmedAssertion is the latest assertion confirmed on the parent chain,
+// validated against its AssertionCreated event.
+type ConfirmedAssertion struct {
+	Hash      common.Hash
+	Node      *rollupcore.RollupCoreAssertionNode
+	Confirmed *rollupcore.RollupCoreAssertionConfirmed
+	Created   *rollupcore.RollupCoreAssertionCreated
+}
+
+// Anchor is the child chain block committed to by a confirmed assertion,
+// together with the provers that served it correctly.
+type Anchor struct {
+	Assertion *ConfirmedAssertion
+	Header    *types.Header
+	Provers   []*ArbitrumClient
+}
+
+// Head is the outcome of a full verification run.
+type Head struct {
+	Anchor     *Anchor
+	Tournament *TournamentResult
+}
+
+// New validates config, opens the store if one is configured and connects to
+// the parent chain and every prover.
+func New(ctx context.Context, config *Config) (*LightClient, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Verified code is content addressed, so all provers share one cache
+	codeCache, err := NewCodeCache(defaultCodeCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var store *Store
+	if config.Store.Dir != "" {
+		store, err = OpenStore(config.Store.Dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Batches are read from the parent chain, so they are shared as well
+	batchCache, err := NewBatchCache(&config.BatchCache, store)
+	if err != nil {
+		if store != nil {
+			store.Close()
+		}
+		return nil, err
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, config.Timeouts.Dial)
+	defer cancel()
+
+	parentChain, err := NewParentChainClient(dialCtx, &config.ParentChain, config.Transport)
+	if err != nil {
+		if store != nil {
+			store.Close()
+		}
+		return nil, fmt.Errorf("failed to connect to the parent chain: %w", err)
+	}
+	ethClient, err := NewEthereumClient(parentChain, config.Chain.RollupCore())
+	if err != nil {
+		parentChain.Close()
+		if store != nil {
+			store.Close()
+		}
+		return nil, fmt.Errorf("failed to init Ethereum client: %w", err)
+	}
+
+	var dasReader *DASReader
+	if config.DataAvailability.Enable {
+		addresses, err := rollupAddresses(config)
+		if err == nil {
+			dasReader, err = NewDASReader(ctx, &config.DataAvailability, parentChain, addresses.SequencerInbox)
+		}
+		if err != nil {
+			ethClient.Close()
+			if store != nil {
+				store.Close()
+			}
+			return nil, fmt.Errorf("failed to init DAS reader: %w", err)
+		}
+	}
+
+	provers := make([]*ArbitrumClient, len(config.Provers))
+	for i, proverURL := range config.Provers {
+		provers[i], err = NewArbitrumClient(dialCtx, proverURL, config.Transport)
+		if err != nil {
+			for _, prover := range provers[:i] {
+				prover.Close()
+			}
+			if dasReader != nil {
+				dasReader.Close()
+			}
+			ethClient.Close()
+			if store != nil {
+				store.Close()
+			}
+			return nil, fmt.Errorf("failed to init Arbitrum client %s: %w", proverURL, err)
+		}
+		provers[i].codeCache = codeCache
+		provers[i].batchCache = batchCache
+		provers[i].parentChain = parentChain
+		provers[i].dasReader = dasReader
+	}
+
+	return &LightClient{
+		config:    config,
+		ethClient: ethClient,
+		provers:   provers,
+		store:     store,
+		dasReader: dasReader,
+	}, nil
+}
+
+func (lc *LightClient) Close() {
+	for _, prover := range lc.provers {
+		prover.Close()
+	}
+	if lc.dasReader != nil {
+		lc.dasReader.Close()
+	}
+	lc.ethClient.Close()
+	if lc.store != nil {
+		if err := lc.store.Close(); err != nil {
+			log.Warn("Failed to close store", "err", err)
+		}
+	}
+}
+
+func (lc *LightClient) Config() *Config {
+	return lc.config
+}
+
+func (lc *LightClient) Provers() []*ArbitrumClient {
+	return lc.provers
+}
+
+func (lc *LightClient) EthereumClient() *EthereumClient {
+	return lc.ethClient
+}
+
+// Store returns the store of verified data, or nil if none is configured.
+func (lc *LightClient) Store() *Store {
+	return lc.store
+}
+
+// LatestConfirmedAssertion fetches the latest confirmed assertion together
+// with its confirmation and creation events. If the assertion is already in
+// the store, its events are taken from there instead of the parent chain logs.
+func (lc *LightClient) LatestConfirmedAssertion(ctx context.Context) (*ConfirmedAssertion, error) {
+	latestAssertion, err := lc.ethClient.GetLatestAssertion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest assertion: %w", err)
+	}
+
+	if lc.store != nil {
+		assertion, err := lc.storedAssertion(ctx, latestAssertion)
+		if err == nil {
+			log.Debug("Resuming from stored assertion", "hash", common.Hash(latestAssertion))
+			return assertion, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			log.Warn("Failed to load stored assertion", "hash", common.Hash(latestAssertion), "err", err)
+		}
+	}
+
+	assertionNode, err := lc.ethClient.GetAssertionDetails(ctx, latestAssertion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assertion details: %w", err)
+	}
+
+	// Both events are looked up by the hash read above, so a confirmation
+	// landing in between cannot mix two assertions
+	confirmedLog, err := lc.ethClient.GetAssertionConfirmedLog(ctx, latestAssertion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AssertionConfirmed: %w", err)
+	}
+
+	createdLog, err := lc.ethClient.GetAssertionCreatedLog(ctx, latestAssertion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AssertionCreated: %w", err)
+	}
+	if confirmedLog.AssertionHash != latestAssertion || createdLog.AssertionHash != latestAssertion {
+		return nil, fmt.Errorf("events do not belong to assertion %s", common.Hash(latestAssertion).Hex())
+	}
+	if err := CheckConfirmedState(confirmedLog, createdLog); err != nil {
+		return nil, err
+	}
+
+	assertion := &ConfirmedAssertion{
+		Hash:      latestAssertion,
+		Node:      assertionNode,
+		Confirmed: confirmedLog,
+		Created:   createdLog,
+	}
+	if lc.store != nil {
+		if err := lc.store.PutAssertion(assertion); err != nil {
+			log.Warn("Failed to store assertion", "hash", assertion.Hash, "err", err)
+		}
+	}
+	return assertion, nil
+}
+
+// storedAssertion decodes the events of a stored assertion and refreshes its
+// node from the parent chain.
+func (lc *LightClient) storedAssertion(ctx context.Context, hash common.Hash) (*ConfirmedAssertion, error) {
+	stored, err := lc.store.Assertion(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmed, err := lc.ethClient.rollupCore.ParseAssertionConfirmed(stored.Confirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode stored AssertionConfirmed: %w", err)
+	}
+	created, err := lc.ethClient.rollupCore.ParseAssertionCreated(stored.Created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode stored AssertionCreated: %w", err)
+	}
+	if confirmed.AssertionHash != hash || created.AssertionHash != hash {
+		return nil, fmt.Errorf("stored events do not belong to assertion %s", hash.Hex())
+	}
+	if err := CheckConfirmedState(confirmed, created); err != nil {
+		return nil, err
+	}
+
+	assertionNode, err := lc.ethClient.GetAssertionDetails(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assertion details: %w", err)
+	}
+
+	return &ConfirmedAssertion{
+		Hash:      hash,
+		Node:      assertionNode,
+		Confirmed: confirmed,
+		Created:   created,
+	}, nil
+}
+
+// VerifyAnchor asks every prover for the block committed to by the assertion
+// and keeps only those whose header hashes to the confirmed block hash. It
+// also ties the sequencer inbox the batches are read from to the assertion.
+func (lc *LightClient) VerifyAnchor(ctx context.Context, assertion *ConfirmedAssertion) (*Anchor, error) {
+	anchor := &Anchor{Assertion: assertion}
+
+	for _, prover := range lc.provers {
+		block, err := prover.GetBlockByHash(ctx, assertion.Confirmed.BlockHash)
+		if err != nil {
+			continue
+		}
+		if !prover.VerifyBlockHash(block.Header(), assertion.Confirmed.BlockHash) {
+			continue
+		}
+		anchor.Provers = append(anchor.Provers, prover)
+		if anchor.Header == nil {
+			anchor.Header = block.Header()
+		}
+	}
+
+	if anchor.Header == nil || len(anchor.Provers) < lc.config.Trust.MinAgreeingProvers {
+		return nil, fmt.Errorf("%w: %d of %d required", ErrNoHonestProver, len(anchor.Provers), lc.config.Trust.MinAgreeingProvers)
+	}
+	if err := lc.VerifyInboxAccumulator(ctx, assertion); err != nil {
+		return nil, err
+	}
+	if lc.store != nil {
+		if err := lc.store.PutHeader(anchor.Header); err != nil {
+			log.Warn("Failed to store anchor header", "hash", anchor.Header.Hash(), "err", err)
+		}
+	}
+	return anchor, nil
+}
+
+// Tournament runs the prover tournament from the anchor. With n == 0 the
+// provers are compared at their latest state, otherwise at block n.
+func (lc *LightClient) Tournament(ctx context.Context, anchor *Anchor, n uint64) (*TournamentResult, error) {
+	result, err := Tournament(ctx, *anchor.Header, anchor.Provers, lc.config, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if lc.store != nil {
+		record := &TournamentRecord{
+			Time:            time.Now(),
+			AnchorAssertion: anchor.Assertion.Hash,
+			AnchorNumber:    anchor.Header.Number.Uint64(),
+			AnchorHash:      anchor.Header.Hash(),
+			At:              n,
+			Survivors:       make([]TournamentRecordSurvivor, 0, len(result.Survivors)),
+		}
+		for _, survivor := range result.Survivors {
+			record.Survivors = append(record.Survivors, TournamentRecordSurvivor{
+				Prover:      survivor.Prover.URL(),
+				BlockNumber: survivor.State.L2BlockNumber,
+				BlockHash:   survivor.State.L2BlockHash,
+			})
+		}
+		if err := lc.store.PutTournament(record); err != nil {
+			log.Warn("Failed to store tournament outcome", "err", err)
+		}
+	}
+	return result, nil
+}
+
+// VerifyHead runs the full flow: fetch the latest confirmed assertion, anchor
+// the provers to it and run the tournament over their latest states.
+func (lc *LightClient) VerifyHead(ctx context.Context) (*Head, error) {
+	assertion, err := lc.LatestConfirmedAssertion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor, err := lc.VerifyAnchor(ctx, assertion)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := lc.Tournament(ctx, anchor, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Head{Anchor: anchor, Tournament: result}, nil
+}
+
+// VerifyBlock runs both oracles for block index as served by prover.
+func (lc *LightClient) VerifyBlock(ctx context.Context, prover *ArbitrumClient, index uint64) (bool, error) {
+	return VerifyOracles(ctx, prover, index, lc.config)
+}
+
+// VerifyConsensus checks that the messages prover claims produced blocks
+// index-1 and index are consecutive in the sequencer inbox.
+func (lc *LightClient) VerifyConsensus(ctx context.Context, prover *ArbitrumClient, index uint64) (bool, error) {
+	prevL1Data, err := prover.GetL1DataAt(ctx, index, lc.config.Chain.ID)
+	if err != nil {
+		return false, err
+	}
+	currL1Data, err := prover.GetL1DataAt(ctx, index+1, lc.config.Chain.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return ExecuteConsensusOracle(ctx, *prevL1Data, *currL1Data, lc.config, prover.parentChain, prover.dasReader, prover.batchCache)
+}
+
+// VerifyExecution re-executes the message behind block index on top of the
+// state of block index-1 and compares the result with prover's header.
+func (lc *LightClient) VerifyExecution(ctx context.Context, prover *ArbitrumClient, index uint64) (bool, error) {
+	prevBlock, err := prover.GetBlockByNumber(ctx, big.NewInt(int64(index-1)))
+	if err != nil {
+		return false, err
+	}
+	currBlock, err := prover.GetBlockByNumber(ctx, big.NewInt(int64(index)))
+	if err != nil {
+		return false, err
+	}
+
+	prevL1Data, err := prover.GetL1DataAt(ctx, index, lc.config.Chain.ID)
+	if err != nil {
+		return false, err
+	}
+	currL1Data, err := prover.GetL1DataAt(ctx, index+1, lc.config.Chain.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return executeBlock(ctx, prover, prevBlock.Header(), currBlock.Header(), prevL1Data, currL1Data, lc.config.Chain.ID)
+}
@@ -0,0 +1,478 @@
+package lightclient
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/net"
+	flag "github.com/spf13/pflag"
+)
+
+type MeasurementConfig struct {
+	OutputDir      string                 `koanf:"output-dir"`
+	MeasureNetwork bool                   `koanf:"network"`
+	MeasureSystem  bool                   `koanf:"system"`
+	Tournament     MeasurementRangeConfig `koanf:"tournament"`
+	Oracle         MeasurementRangeConfig `koanf:"oracle"`
+}
+
+// MeasurementRangeConfig selects the blocks blockStart, blockStart+blockStep,
+// ... up to blockEnd, each measured Iterations times.
+type MeasurementRangeConfig struct {
+	Iterations int    `koanf:"iterations"`
+	BlockStart uint64 `koanf:"block-start"`
+	BlockEnd   uint64 `koanf:"block-end"`
+	BlockStep  uint64 `koanf:"block-step"`
+}
+
+var DefaultMeasurementConfig = MeasurementConfig{
+	OutputDir:      "./measurements",
+	MeasureNetwork: true,
+	MeasureSystem:  true,
+	Tournament: MeasurementRangeConfig{
+		Iterations: 5,
+		BlockStart: 100,
+		BlockEnd:   10100,
+		BlockStep:  1000,
+	},
+	Oracle: MeasurementRangeConfig{
+		Iterations: 10,
+		BlockStart: 1000,
+		BlockEnd:   10000,
+		BlockStep:  1000,
+	},
+}
+
+func MeasurementConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".output-dir", DefaultMeasurementConfig.OutputDir, "directory the CSV results are written to, one subdirectory per measurement kind")
+	f.Bool(prefix+".network", DefaultMeasurementConfig.MeasureNetwork, "record network traffic")
+	f.Bool(prefix+".system", DefaultMeasurementConfig.MeasureSystem, "record memory and CPU usage")
+	MeasurementRangeConfigAddOptions(prefix+".tournament", f, DefaultMeasurementConfig.Tournament)
+	MeasurementRangeConfigAddOptions(prefix+".oracle", f, DefaultMeasurementConfig.Oracle)
+}
+
+func MeasurementRangeConfigAddOptions(prefix string, f *flag.FlagSet, defaults MeasurementRangeConfig) {
+	f.Int(prefix+".iterations", defaults.Iterations, "iterations per measured block")
+	f.Uint64(prefix+".block-start", defaults.BlockStart, "first measured block")
+	f.Uint64(prefix+".block-end", defaults.BlockEnd, "last measured block")
+	f.Uint64(prefix+".block-step", defaults.BlockStep, "distance between measured blocks")
+}
+
+func (c *MeasurementConfig) Validate() error {
+	if c.OutputDir == "" {
+		return errors.New("output-dir: must be set")
+	}
+	if err := c.Tournament.Validate(); err != nil {
+		return fmt.Errorf("tournament: %w", err)
+	}
+	if err := c.Oracle.Validate(); err != nil {
+		return fmt.Errorf("oracle: %w", err)
+	}
+	return nil
+}
+
+func (c *MeasurementRangeConfig) Validate() error {
+	if c.Iterations < 1 {
+		return fmt.Errorf("iterations: must be at least 1, got %d", c.Iterations)
+	}
+	if c.BlockStep == 0 {
+		return errors.New("block-step: must be at least 1")
+	}
+	if c.BlockEnd < c.BlockStart {
+		return fmt.Errorf("block-end: %d is below block-start %d", c.BlockEnd, c.BlockStart)
+	}
+	return nil
+}
+
+func (c *MeasurementRangeConfig) blocks() []uint64 {
+	var blocks []uint64
+	for block := c.BlockStart; block <= c.BlockEnd; block += c.BlockStep {
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+type MeasurementResult struct {
+	NumProvers          int
+	BlockNumber         uint64
+	Iteration           int
+	SyncTime            time.Duration
+	ConsensusOracleTime time.Duration
+	ExecutionOracleTime time.Duration
+	MemoryUsage         uint64
+	CPUUsage            float64
+	NetworkBytesIn      uint64
+	NetworkBytesOut     uint64
+	Timestamp           time.Time
+}
+
+type MeasurementRunner struct {
+	config       *MeasurementConfig
+	results      []MeasurementResult
+	arbClient    *ArbitrumClient
+	ctx          context.Context
+	clientConfig *Config
+	outputDir    string
+}
+
+func NewMeasurementRunner(ctx context.Context, clientConfig *Config, arbClient *ArbitrumClient) *MeasurementRunner {
+	return &MeasurementRunner{
+		config:       &clientConfig.Measurement,
+		results:      make([]MeasurementResult, 0),
+		arbClient:    arbClient,
+		ctx:          ctx,
+		clientConfig: clientConfig,
+	}
+}
+
+func (mr *MeasurementRunner) prepareOutputDir(kind string) error {
+	mr.outputDir = filepath.Join(mr.config.OutputDir, kind)
+	if err := os.MkdirAll(mr.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	return nil
+}
+
+func (mr *MeasurementRunner) RunTournamentMeasurements(arbClients []*ArbitrumClient) error {
+	rangeConfig := mr.config.Tournament
+	log.Info("Starting tournament measurements", "iterations", rangeConfig.Iterations)
+
+	if err := mr.prepareOutputDir("tournament"); err != nil {
+		return err
+	}
+
+	genesisBlock, err := mr.arbClient.GetBlockByNumber(mr.ctx, big.NewInt(0))
+	if err != nil {
+		return fmt.Errorf("failed to get genesis block: %v", err)
+	}
+
+	blocks := rangeConfig.blocks()
+	currentIteration := 0
+
+	length := len(arbClients)
+	for _, blockNumber := range blocks {
+		for provers := 0; provers < length; provers++ {
+			for iteration := 0; iteration < rangeConfig.Iterations; iteration++ {
+				currentIteration++
+				log.Info("Tournament iteration", "current", currentIteration, "total", rangeConfig.Iterations*length*len(blocks))
+				var inStart, outStart uint64
+				if mr.config.MeasureNetwork {
+					inStart, outStart, _ = mr.getNetworkBytes()
+				}
+
+				startTime := time.Now()
+
+				if _, err := Tournament(mr.ctx, *genesisBlock.Header(), arbClients[:provers+1], mr.clientConfig, blockNumber); err != nil {
+					log.Warn("Tournament failed", "block", blockNumber, "provers", provers+1, "err", err)
+				}
+
+				result := MeasurementResult{
+					NumProvers:  provers,
+					BlockNumber: blockNumber,
+					Iteration:   iteration,
+					SyncTime:    time.Since(startTime),
+					Timestamp:   time.Now(),
+				}
+
+				if mr.config.MeasureSystem {
+					mr.addSystemMeasurements(&result)
+				}
+
+				if mr.config.MeasureNetwork {
+					inBytes, outBytes, err := mr.getNetworkBytes()
+					if err != nil {
+						log.Warn("Failed to get network bytes", "err", err)
+					}
+					result.NetworkBytesIn = inBytes - inStart
+					result.NetworkBytesOut = outBytes - outStart
+				}
+
+				mr.results = append(mr.results, result)
+
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+		time.Sleep(5000 * time.Millisecond)
+	}
+
+	return mr.saveResults("tournament_measurements.csv")
+}
+
+func (mr *MeasurementRunner) addSystemMeasurements(result *MeasurementResult) {
+	if vmstat, err := mem.VirtualMemory(); err == nil {
+		result.MemoryUsage = vmstat.Used
+	}
+
+	if cpuPercent, err := cpu.Percent(0, false); err == nil && len(cpuPercent) > 0 {
+		result.CPUUsage = cpuPercent[0]
+	}
+}
+
+func (mr *MeasurementRunner) getNetworkBytes() (inBytes, outBytes uint64, err error) {
+	counters, err := net.IOCounters(true)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var totalIn, totalOut uint64
+	for _, c := range counters {
+		totalIn += c.BytesRecv
+		totalOut += c.BytesSent
+	}
+	return totalIn, totalOut, nil
+}
+
+func (mr *MeasurementRunner) saveResults(filename string) error {
+	path := filepath.Join(mr.outputDir, filename)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %v", path, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	header := []string{
+		"num_provers", "block_number", "iteration", "sync_time_ms", "consensus_oracle_time_ms",
+		"execution_oracle_time_ms", "memory_bytes", "cpu_percent",
+		"network_bytes_in", "network_bytes_out", "timestamp",
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %v", err)
+	}
+
+	for _, result := range mr.results {
+		row := []string{
+			strconv.Itoa(result.NumProvers),
+			strconv.FormatUint(result.BlockNumber, 10),
+			strconv.Itoa(result.Iteration),
+			strconv.FormatInt(int64(result.SyncTime.Milliseconds()), 10),
+			strconv.FormatInt(int64(result.ConsensusOracleTime.Milliseconds()), 10),
+			strconv.FormatInt(int64(result.ExecutionOracleTime.Milliseconds()), 10),
+			strconv.FormatUint(result.MemoryUsage, 10),
+			strconv.FormatFloat(result.CPUUsage, 'f', 2, 64),
+			strconv.FormatUint(result.NetworkBytesIn, 10),
+			strconv.FormatUint(result.NetworkBytesOut, 10),
+			result.Timestamp.Format(time.RFC3339),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write row: %v", err)
+		}
+	}
+
+	log.Info("Results saved", "path", path)
+	return nil
+}
+
+func (mr *MeasurementRunner) RunConsensusOracleMeasurements() error {
+	rangeConfig := mr.config.Oracle
+	log.Info("Starting consensus oracle measurements", "iterations", rangeConfig.Iterations)
+
+	if err := mr.prepareOutputDir("consensus"); err != nil {
+		return err
+	}
+
+	for _, testBlock := range rangeConfig.blocks() {
+		for iteration := 1; iteration <= rangeConfig.Iterations; iteration++ {
+			log.Info("Consensus oracle iteration", "block", testBlock, "current", iteration, "total", rangeConfig.Iterations)
+
+			result := MeasurementResult{
+				BlockNumber: testBlock,
+				Iteration:   iteration,
+				Timestamp:   time.Now(),
+			}
+
+			prevTrackingL1Data, err := mr.arbClient.GetL1DataAt(mr.ctx, testBlock, mr.clientConfig.Chain.ID)
+			if err != nil {
+				log.Warn("Failed to get prev L1 data", "err", err)
+				continue
+			}
+			currTrackingL1Data, err := mr.arbClient.GetL1DataAt(mr.ctx, testBlock+1, mr.clientConfig.Chain.ID)
+			if err != nil {
+				log.Warn("Failed to get curr L1 data", "err", err)
+				continue
+			}
+
+			var inStart, outStart uint64
+			if mr.config.MeasureNetwork {
+				inStart, outStart, _ = mr.getNetworkBytes()
+			}
+
+			start := time.Now()
+			_, err = ExecuteConsensusOracle(mr.ctx, *prevTrackingL1Data, *currTrackingL1Data, mr.clientConfig, mr.arbClient.parentChain, mr.arbClient.dasReader, mr.arbClient.batchCache)
+			result.ConsensusOracleTime = time.Since(start)
+
+			if err != nil {
+				log.Warn("Consensus oracle error", "err", err)
+			}
+
+			if mr.config.MeasureSystem {
+				mr.addSystemMeasurements(&result)
+			}
+
+			if mr.config.MeasureNetwork {
+				inBytes, outBytes, err := mr.getNetworkBytes()
+				if err != nil {
+					log.Warn("Failed to get network bytes", "err", err)
+				}
+				result.NetworkBytesIn = inBytes - inStart
+				result.NetworkBytesOut = outBytes - outStart
+			}
+
+			mr.results = append(mr.results, result)
+
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	return mr.saveResults("consensus_oracle_measurements.csv")
+}
+
+func (mr *MeasurementRunner) RunExecutionOracleMeasurements() error {
+	rangeConfig := mr.config.Oracle
+	log.Info("Starting execution oracle measurements", "iterations", rangeConfig.Iterations)
+
+	if err := mr.prepareOutputDir("execution"); err != nil {
+		return err
+	}
+
+	for _, testBlock := range rangeConfig.blocks() {
+		for iteration := 1; iteration <= rangeConfig.Iterations; iteration++ {
+			log.Info("Execution oracle iteration", "block", testBlock, "current", iteration, "total", rangeConfig.Iterations)
+
+			result := MeasurementResult{
+				BlockNumber: testBlock,
+				Iteration:   iteration,
+				Timestamp:   time.Now(),
+			}
+
+			prevBlock, err := mr.arbClient.GetBlockByNumber(mr.ctx, big.NewInt(int64(testBlock-1)))
+			if err != nil {
+				log.Warn("Failed to get prev block", "err", err)
+				continue
+			}
+
+			currBlock, err := mr.arbClient.GetBlockByNumber(mr.ctx, big.NewInt(int64(testBlock)))
+			if err != nil {
+				log.Warn("Failed to get curr block", "err", err)
+				continue
+			}
+
+			currTrackingL1Data, err := mr.arbClient.GetL1DataAt(mr.ctx, testBlock+1, mr.clientConfig.Chain.ID)
+			if err != nil {
+				log.Warn("Failed to get curr L1 data", "err", err)
+				continue
+			}
+
+			var inStart, outStart uint64
+			if mr.config.MeasureNetwork {
+				inStart, outStart, _ = mr.getNetworkBytes()
+			}
+
+			start := time.Now()
+			executionResult, err := ExecuteExecutionOracle(mr.ctx, mr.arbClient, prevBlock.Header(), &currTrackingL1Data.Message, currBlock.Header(), mr.clientConfig.Chain.ID)
+			result.ExecutionOracleTime = time.Since(start)
+
+			if !executionResult {
+				log.Warn("Execution oracle failed", "iteration", iteration, "err", err)
+			}
+
+			if mr.config.MeasureSystem {
+				mr.addSystemMeasurements(&result)
+			}
+
+			if mr.config.MeasureNetwork {
+				inBytes, outBytes, err := mr.getNetworkBytes()
+				if err != nil {
+					log.Warn("Failed to get network bytes", "err", err)
+				}
+				result.NetworkBytesIn = inBytes - inStart
+				result.NetworkBytesOut = outBytes - outStart
+			}
+
+			mr.results = append(mr.results, result)
+
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	return mr.saveResults("execution_oracle_measurements.csv")
+}
+
+type MeasurementStats struct {
+	Avg float64 `json:"avg"`
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type MeasurementSummary struct {
+	Total             int               `json:"total"`
+	ConsensusOracleMs *MeasurementStats `json:"consensusOracleMs,omitempty"`
+	ExecutionOracleMs *MeasurementStats `json:"executionOracleMs,omitempty"`
+	SyncTimeMs        *MeasurementStats `json:"syncTimeMs,omitempty"`
+	MemoryBytes       *MeasurementStats `json:"memoryBytes,omitempty"`
+	CPUPercent        *MeasurementStats `json:"cpuPercent,omitempty"`
+}
+
+// Summary aggregates the collected results. Metrics without any non-zero
+// sample are left nil.
+func (mr *MeasurementRunner) Summary() *MeasurementSummary {
+	summary := &MeasurementSummary{Total: len(mr.results)}
+
+	stats := func(metric func(MeasurementResult) float64) *MeasurementStats {
+		var values []float64
+		for _, result := range mr.results {
+			if v := metric(result); v > 0 {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		avg, min, max := calculateStats(values)
+		return &MeasurementStats{Avg: avg, Min: min, Max: max}
+	}
+
+	summary.ConsensusOracleMs = stats(func(r MeasurementResult) float64 { return float64(r.ConsensusOracleTime.Milliseconds()) })
+	summary.ExecutionOracleMs = stats(func(r MeasurementResult) float64 { return float64(r.ExecutionOracleTime.Milliseconds()) })
+	summary.SyncTimeMs = stats(func(r MeasurementResult) float64 { return float64(r.SyncTime.Milliseconds()) })
+	summary.MemoryBytes = stats(func(r MeasurementResult) float64 { return float64(r.MemoryUsage) })
+	summary.CPUPercent = stats(func(r MeasurementResult) float64 { return r.CPUUsage })
+
+	return summary
+}
+
+func calculateStats(values []float64) (avg, min, max float64) {
+	if len(values) == 0 {
+		return 0, 0, 0
+	}
+
+	min = values[0]
+	max = values[0]
+	sum := 0.0
+
+	for _, v := range values {
+		sum += v
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	avg = sum / float64(len(values))
+	return avg, min, max
+}
@@ -0,0 +1,183 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/offchainlabs/nitro/solgen/go/node_interfacegen"
+	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+)
+
+var ErrOutboxProof = errors.New("outbox proof does not verify against the send root")
+var ErrWithdrawalNotConfirmed = errors.New("withdrawal is not covered by the confirmed send root yet")
+var ErrNoWithdrawal = errors.New("transaction did not send any L2 to L1 message")
+
+var (
+	arbSysAddress        = common.HexToAddress("0x64") // ArbSysAddress
+	nodeInterfaceAddress = common.HexToAddress("0xc8") // NodeInterfaceAddress
+)
+
+var l2ToL1TxTopic = crypto.Keccak256Hash([]byte("L2ToL1Tx(address,address,uint256,uint256,uint256,uint256,uint256,uint256,bytes)"))
+
+// maxOutboxProofLength is the limit the outbox contract puts on proofs.
+const maxOutboxProofLength = 255
+
+// WithdrawalProof is an L2 to L1 message together with its proof against the
+// send root of a confirmed assertion. The outbox on the parent chain accepts
+// the same proof, so the message can be executed there.
+type WithdrawalProof struct {
+	Caller      common.Address `json:"caller"`
+	Destination common.Address `json:"destination"`
+	ArbBlockNum uint64         `json:"arbBlockNum"`
+	EthBlockNum uint64         `json:"ethBlockNum"`
+	Timestamp   uint64         `json:"timestamp"`
+	CallValue   *big.Int       `json:"callValue"`
+	Data        hexutil.Bytes  `json:"data"`
+
+	Position  uint64        `json:"position"`
+	ItemHash  common.Hash   `json:"itemHash"`
+	Assertion common.Hash   `json:"assertion"`
+	SendRoot  common.Hash   `json:"sendRoot"`
+	SendCount uint64        `json:"sendCount"`
+	Proof     []common.Hash `json:"proof"`
+}
+
+// OutboxItemHash is the hash ArbSys emits for an L2 to L1 message and the
+// outbox recomputes before checking the proof.
+func OutboxItemHash(event *precompilesgen.ArbSysL2ToL1Tx) common.Hash {
+	return crypto.Keccak256Hash(
+		event.Caller.Bytes(),
+		event.Destination.Bytes(),
+		math.U256Bytes(new(big.Int).Set(event.ArbBlockNum)),
+		math.U256Bytes(new(big.Int).Set(event.EthBlockNum)),
+		math.U256Bytes(new(big.Int).Set(event.Timestamp)),
+		math.U256Bytes(new(big.Int).Set(event.Callvalue)),
+		event.Data,
+	)
+}
+
+// VerifyOutboxProof recomputes the send root from the item at position the
+// same way the outbox contract does. Leaves of the send merkle accumulator
+// are the hashes of the item hashes.
+func VerifyOutboxProof(sendRoot common.Hash, position uint64, itemHash common.Hash, proof []common.Hash) error {
+	if len(proof) > maxOutboxProofLength {
+		return fmt.Errorf("%w: proof of %d nodes is too long", ErrOutboxProof, len(proof))
+	}
+	if len(proof) < 64 && position>>len(proof) != 0 {
+		return fmt.Errorf("%w: position %d does not fit a proof of %d nodes", ErrOutboxProof, position, len(proof))
+	}
+	node := crypto.Keccak256Hash(itemHash.Bytes())
+	path := position
+	for _, sibling := range proof {
+		if path&1 == 0 {
+			node = crypto.Keccak256Hash(node.Bytes(), sibling.Bytes())
+		} else {
+			node = crypto.Keccak256Hash(sibling.Bytes(), node.Bytes())
+		}
+		path >>= 1
+	}
+	if node != sendRoot {
+		return fmt.Errorf("%w: computed %s, send root %s", ErrOutboxProof, node.Hex(), sendRoot.Hex())
+	}
+	return nil
+}
+
+// ProveWithdrawals builds and verifies the outbox proofs of every L2 to L1
+// message sent by the transaction txHash against the send root confirmed in
+// the assertion of anchor. The anchor header binds the send root to the
+// number of messages sent so far. The first prover whose proofs verify is
+// used.
+func (lc *LightClient) ProveWithdrawals(ctx context.Context, anchor *Anchor, txHash common.Hash) ([]*WithdrawalProof, error) {
+	sendRoot := common.Hash(anchor.Assertion.Confirmed.SendRoot)
+	info := types.DeserializeHeaderExtraInformation(anchor.Header)
+	if info.SendRoot != sendRoot {
+		return nil, fmt.Errorf("%w: anchor header send root %s, confirmed %s", ErrOutboxProof, info.SendRoot.Hex(), sendRoot.Hex())
+	}
+
+	var errs []error
+	for _, prover := range anchor.Provers {
+		proofs, err := prover.proveWithdrawals(ctx, txHash, sendRoot, info.SendCount)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("prover %s: %w", prover.URL(), err))
+			continue
+		}
+		for _, proof := range proofs {
+			proof.Assertion = anchor.Assertion.Hash
+		}
+		return proofs, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (c *ArbitrumClient) proveWithdrawals(ctx context.Context, txHash common.Hash, sendRoot common.Hash, sendCount uint64) ([]*WithdrawalProof, error) {
+	receipt, err := c.ethClient.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipt of %s: %w", txHash.Hex(), err)
+	}
+	arbSys, err := precompilesgen.NewArbSysFilterer(arbSysAddress, c.ethClient)
+	if err != nil {
+		return nil, err
+	}
+	nodeInterface, err := node_interfacegen.NewNodeInterfaceCaller(nodeInterfaceAddress, c.ethClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var proofs []*WithdrawalProof
+	for _, l := range receipt.Logs {
+		if l.Address != arbSysAddress || len(l.Topics) == 0 || l.Topics[0] != l2ToL1TxTopic {
+			continue
+		}
+		event, err := arbSys.ParseL2ToL1Tx(*l)
+		if err != nil {
+			return nil, err
+		}
+		itemHash := OutboxItemHash(event)
+		if common.BigToHash(event.Hash) != itemHash {
+			return nil, fmt.Errorf("%w: event hash %s, computed %s", ErrOutboxProof, common.BigToHash(event.Hash).Hex(), itemHash.Hex())
+		}
+		position := event.Position.Uint64()
+		if position >= sendCount {
+			return nil, fmt.Errorf("%w: position %d, %d messages confirmed", ErrWithdrawalNotConfirmed, position, sendCount)
+		}
+
+		outboxProof, err := nodeInterface.ConstructOutboxProof(&bind.CallOpts{Context: ctx}, sendCount, position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct outbox proof for position %d: %w", position, err)
+		}
+		proof := make([]common.Hash, len(outboxProof.Proof))
+		for i, node := range outboxProof.Proof {
+			proof[i] = node
+		}
+		if err := VerifyOutboxProof(sendRoot, position, itemHash, proof); err != nil {
+			return nil, err
+		}
+
+		proofs = append(proofs, &WithdrawalProof{
+			Caller:      event.Caller,
+			Destination: event.Destination,
+			ArbBlockNum: event.ArbBlockNum.Uint64(),
+			EthBlockNum: event.EthBlockNum.Uint64(),
+			Timestamp:   event.Timestamp.Uint64(),
+			CallValue:   event.Callvalue,
+			Data:        event.Data,
+			Position:    position,
+			ItemHash:    itemHash,
+			SendRoot:    sendRoot,
+			SendCount:   sendCount,
+			Proof:       proof,
+		})
+	}
+	if len(proofs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoWithdrawal, txHash.Hex())
+	}
+	return proofs, nil
+}
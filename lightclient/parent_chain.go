@@ -0,0 +1,343 @@
+package lightclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/time/rate"
+)
+
+var ErrParentChainUnavailable = errors.New("parent chain request failed on every attempt")
+var ErrTransportNeedsHTTP = errors.New("a custom transport only works with HTTP endpoints, not WebSocket or IPC")
+
+// ParentChainClient is the connection to the parent chain shared by the
+// assertion reader, the batch handler and the blob client. Over HTTP, every
+// request goes through failoverTransport, which rate limits it and retries it
+// with exponential backoff, moving on to the next endpoint after each failure.
+// Log queries are split into ranges the providers accept.
+type ParentChainClient struct {
+	client *ethclient.Client
+
+	// maxLogRange starts at the configured limit and is lowered whenever a
+	// provider rejects a range, so later queries don't fail the same way.
+	maxLogRange atomic.Uint64
+}
+
+// NewParentChainClient connects to the parent chain. base carries the HTTP
+// requests the failover transport sends; nil uses http.DefaultTransport.
+func NewParentChainClient(ctx context.Context, config *ParentChainConfig, base http.RoundTripper) (*ParentChainClient, error) {
+	var rpcClient *rpc.Client
+	if isHTTPURL(config.URL) {
+		transport, err := newFailoverTransport(config, base)
+		if err != nil {
+			return nil, err
+		}
+		rpcClient, err = rpc.DialOptions(ctx, config.URL, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if base != nil {
+			return nil, ErrTransportNeedsHTTP
+		}
+		var err error
+		rpcClient, err = rpc.DialContext(ctx, config.URL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c := &ParentChainClient{client: ethclient.NewClient(rpcClient)}
+	c.maxLogRange.Store(config.MaxLogRange)
+	return c, nil
+}
+
+// Client returns the shared client, for contract bindings and nitro code that
+// take an *ethclient.Client.
+func (c *ParentChainClient) Client() *ethclient.Client {
+	return c.client
+}
+
+func (c *ParentChainClient) Close() {
+	c.client.Close()
+}
+
+// FilterLogs runs q over the blocks from to to, split into ranges of at most
+// the current log range limit. Logs are returned in order.
+func (c *ParentChainClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery, from uint64, to uint64) ([]types.Log, error) {
+	var all []types.Log
+	for start := from; start <= to; {
+		end := to
+		if span := c.maxLogRange.Load(); end-start >= span {
+			end = start + span - 1
+		}
+		logs, err := c.filterRange(ctx, q, start, end)
+		if err != nil {
+			if errors.Is(err, errLogRangeRejected) {
+				continue
+			}
+			return nil, err
+		}
+		all = append(all, logs...)
+		if end == to {
+			break
+		}
+		start = end + 1
+	}
+	return all, nil
+}
+
+// LatestLogs walks back from block to down to block from and returns the logs
+// of the newest range in which q matches anything, or nil if it matches
+// nothing at all.
+func (c *ParentChainClient) LatestLogs(ctx context.Context, q ethereum.FilterQuery, from uint64, to uint64) ([]types.Log, error) {
+	for end := to; end >= from; {
+		start := from
+		if span := c.maxLogRange.Load(); end-start >= span {
+			start = end - span + 1
+		}
+		logs, err := c.filterRange(ctx, q, start, end)
+		if err != nil {
+			if errors.Is(err, errLogRangeRejected) {
+				continue
+			}
+			return nil, err
+		}
+		if len(logs) > 0 {
+			return logs, nil
+		}
+		if start == from {
+			break
+		}
+		end = start - 1
+	}
+	return nil, nil
+}
+
+var errLogRangeRejected = errors.New("log range rejected")
+
+// filterRange queries a single range. If the provider rejects it as too large
+// the log range limit is halved and errLogRangeRejected returned, so the
+// caller retries with a smaller range.
+func (c *ParentChainClient) filterRange(ctx context.Context, q ethereum.FilterQuery, from uint64, to uint64) ([]types.Log, error) {
+	q.BlockHash = nil
+	q.FromBlock = new(big.Int).SetUint64(from)
+	q.ToBlock = new(big.Int).SetUint64(to)
+	logs, err := c.client.FilterLogs(ctx, q)
+	if err == nil {
+		return logs, nil
+	}
+	span := to - from + 1
+	if span == 1 || !isLogRangeError(err) {
+		return nil, fmt.Errorf("failed to get logs in blocks %d-%d: %w", from, to, err)
+	}
+	limit := span / 2
+	for {
+		current := c.maxLogRange.Load()
+		if current <= limit || c.maxLogRange.CompareAndSwap(current, limit) {
+			break
+		}
+	}
+	log.Info("Parent chain rejected log range, lowering it", "blocks", span, "limit", c.maxLogRange.Load(), "err", err)
+	return nil, errLogRangeRejected
+}
+
+// isLogRangeError recognizes the errors providers return when a log query
+// spans too many blocks or matches too many logs. There is no standard for
+// these, so this goes by the code some providers use and by wording.
+func isLogRangeError(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32005 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, hint := range []string{"block range", "range too large", "range is too large", "too many", "more than", "response size", "limit exceeded"} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// failoverTransport sends JSON-RPC requests to the first healthy endpoint. A
+// request that fails at the HTTP level, is answered with 429 or a server
+// error, or gets a JSON-RPC rate limit error with status 200, is retried after
+// a growing delay on the next endpoint, which then stays current until it
+// fails in turn.
+type failoverTransport struct {
+	endpoints  []*url.URL
+	current    atomic.Int64
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	limiter    *rate.Limiter
+	base       http.RoundTripper
+}
+
+func newFailoverTransport(config *ParentChainConfig, base http.RoundTripper) (*failoverTransport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &failoverTransport{
+		retries:    config.Retries,
+		backoff:    config.RetryBackoff,
+		maxBackoff: config.MaxRetryBackoff,
+		base:       base,
+	}
+	for _, raw := range config.Endpoints() {
+		endpoint, err := url.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		t.endpoints = append(t.endpoints, endpoint)
+	}
+	if config.RequestsPerSecond > 0 {
+		t.limiter = rate.NewLimiter(rate.Limit(config.RequestsPerSecond), max(1, int(config.RequestsPerSecond)))
+	}
+	return t, nil
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := req.Context()
+	backoff := t.backoff
+	var lastErr error
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, t.maxBackoff)
+		}
+		if t.limiter != nil {
+			if err := t.limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		index := t.current.Load()
+		endpoint := t.endpoints[index]
+		resp, err := t.base.RoundTrip(t.requestTo(req, endpoint, body))
+		if err == nil {
+			err = checkResponse(resp)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+		next := (index + 1) % int64(len(t.endpoints))
+		t.current.CompareAndSwap(index, next)
+		// Endpoint URLs often carry API keys, so only the host is logged
+		log.Debug("Parent chain request failed", "host", endpoint.Host, "attempt", attempt+1, "err", err)
+	}
+	return nil, fmt.Errorf("%w: %w", ErrParentChainUnavailable, lastErr)
+}
+
+var errRateLimited = errors.New("rate limited")
+
+// rpcErrorResponse is the part of a JSON-RPC response checkResponse needs.
+type rpcErrorResponse struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// checkResponse returns an error, after closing the body, if resp is a 429 or
+// a server error, or if it is a 200 whose body, or any response of a batch
+// in it, is a JSON-RPC rate limit error. Otherwise the body is left readable
+// for the caller. Providers don't agree on a code for rate limits: -32005 is
+// also what some of them return for log queries that are too large, which
+// filterRange handles, so only the wording is relied on.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return errors.New(resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if !bytes.Contains(body, []byte(`"error"`)) {
+		return nil
+	}
+
+	var responses []rpcErrorResponse
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &responses); err != nil {
+			return nil
+		}
+	} else {
+		var single rpcErrorResponse
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil
+		}
+		responses = append(responses, single)
+	}
+	for _, response := range responses {
+		if response.Error != nil && isRateLimitMessage(response.Error.Message) {
+			return fmt.Errorf("%w: %d %s", errRateLimited, response.Error.Code, response.Error.Message)
+		}
+	}
+	return nil
+}
+
+func isRateLimitMessage(message string) bool {
+	msg := strings.ToLower(message)
+	for _, hint := range []string{"rate limit", "rate exceeded", "too many requests", "request count exceeded", "exceeded its compute units"} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *failoverTransport) requestTo(req *http.Request, endpoint *url.URL, body []byte) *http.Request {
+	out := req.Clone(req.Context())
+	target := *endpoint
+	out.URL = &target
+	out.Host = target.Host
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return out
+}
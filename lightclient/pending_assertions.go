@@ -0,0 +1,175 @@
+package lightclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	rollupcore "github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/rollup-core"
+)
+
+// Trust labels reported next to assertion states.
+const (
+	TrustConfirmed = "confirmed"
+	TrustPending   = "pending"
+)
+
+// blockTimeSampleBlocks is the number of recent parent chain blocks whose
+// timestamps are used to estimate the parent chain block time.
+const blockTimeSampleBlocks = 1000
+
+// PendingAssertion is an assertion created on top of the latest confirmed one
+// that is not confirmed yet. Its state is only optimistic: it holds unless a
+// challenge proves it wrong before it is confirmed.
+type PendingAssertion struct {
+	Hash                  common.Hash `json:"hash"`
+	ParentHash            common.Hash `json:"parentAssertionHash"`
+	BlockHash             common.Hash `json:"blockHash"`
+	SendRoot              common.Hash `json:"sendRoot"`
+	Batch                 uint64      `json:"batch"`
+	PosInBatch            uint64      `json:"posInBatch"`
+	CreatedAtBlock        uint64      `json:"createdAtBlock"`
+	ConfirmableAtBlock    uint64      `json:"confirmableAtBlock"`
+	EstimatedConfirmation time.Time   `json:"estimatedConfirmation"`
+	Challenged            bool        `json:"challenged"`
+	Trust                 string      `json:"trust"`
+
+	Created *rollupcore.RollupCoreAssertionCreated `json:"-"`
+}
+
+// PendingAssertions returns the unconfirmed assertions descending from the
+// confirmed assertion created by confirmed, ordered by creation. Every one is
+// checked to hash correctly and to link to its parent.
+func (ec *EthereumClient) PendingAssertions(ctx context.Context, confirmed *rollupcore.RollupCoreAssertionCreated) ([]*PendingAssertion, error) {
+	head, err := ec.provider.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Children can be created in the same parent chain block as confirmed
+	created, err := ec.assertionsCreatedSince(ctx, confirmed.Raw.BlockNumber, head.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	blockTime, err := ec.averageBlockTime(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[common.Hash]*rollupcore.RollupCoreAssertionCreated{common.Hash(confirmed.AssertionHash): confirmed}
+	var pending []*PendingAssertion
+	for _, assertion := range created {
+		hash := common.Hash(assertion.AssertionHash)
+		if hash == common.Hash(confirmed.AssertionHash) {
+			continue
+		}
+		parent, ok := known[common.Hash(assertion.ParentAssertionHash)]
+		if !ok {
+			// Rivals of confirmed assertions and their descendants
+			continue
+		}
+		generatedHash, err := GenerateAssertionHash(assertion.ParentAssertionHash, assertion.Assertion.AfterState, assertion.AfterInboxBatchAcc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate assertion hash: %w", err)
+		}
+		if generatedHash != hash {
+			return nil, fmt.Errorf("mismatched assertion hash: expected %x, got %x", hash, generatedHash)
+		}
+		if err := checkAssertionLink(parent, assertion); err != nil {
+			return nil, err
+		}
+
+		node, err := ec.GetAssertionDetails(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get assertion details: %w", err)
+		}
+		known[hash] = assertion
+		if node.Status != AssertionStatusPending {
+			log.Debug("Skipping assertion that is no longer pending", "hash", hash, "status", node.Status)
+			continue
+		}
+		parentNode, err := ec.GetAssertionDetails(ctx, assertion.ParentAssertionHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get assertion details: %w", err)
+		}
+
+		// The rollup confirms an assertion after the confirm period its parent
+		// was created with
+		confirmableAt := node.CreatedAtBlock + parent.ConfirmPeriodBlocks
+		state := GlobalState(assertion.Assertion.AfterState.GlobalState)
+		pending = append(pending, &PendingAssertion{
+			Hash:                  hash,
+			ParentHash:            assertion.ParentAssertionHash,
+			BlockHash:             state.BlockHash(),
+			SendRoot:              state.SendRoot(),
+			Batch:                 state.Batch(),
+			PosInBatch:            state.PosInBatch(),
+			CreatedAtBlock:        node.CreatedAtBlock,
+			ConfirmableAtBlock:    confirmableAt,
+			EstimatedConfirmation: estimateBlockTime(head.Number.Uint64(), head.Time, confirmableAt, blockTime),
+			Challenged:            parentNode.SecondChildBlock != 0,
+			Trust:                 TrustPending,
+			Created:               assertion,
+		})
+	}
+	return pending, nil
+}
+
+// assertionsCreatedSince returns the AssertionCreated events in the parent
+// chain blocks from to to, in order.
+func (ec *EthereumClient) assertionsCreatedSince(ctx context.Context, from uint64, to uint64) ([]*rollupcore.RollupCoreAssertionCreated, error) {
+	logs, err := ec.parentChain.FilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: []common.Address{ec.contractAddr},
+		Topics:    [][]common.Hash{{assertionCreatedTopic}},
+	}, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AssertionCreated: %w", err)
+	}
+	created := make([]*rollupcore.RollupCoreAssertionCreated, 0, len(logs))
+	for _, l := range logs {
+		assertion, err := ec.rollupCore.ParseAssertionCreated(l)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, assertion)
+	}
+	return created, nil
+}
+
+// averageBlockTime estimates the parent chain block time from the timestamps
+// of head and of the block blockTimeSampleBlocks before it, so it also holds
+// for parent chains other than Ethereum.
+func (ec *EthereumClient) averageBlockTime(ctx context.Context, head *types.Header) (time.Duration, error) {
+	number := head.Number.Uint64()
+	blocks := min(number, blockTimeSampleBlocks)
+	if blocks == 0 {
+		return 0, nil
+	}
+	past, err := ec.provider.HeaderByNumber(ctx, new(big.Int).SetUint64(number-blocks))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get parent chain header %d: %w", number-blocks, err)
+	}
+	if past.Time > head.Time {
+		return 0, fmt.Errorf("parent chain block %d is newer than the head", past.Number.Uint64())
+	}
+	return time.Duration(head.Time-past.Time) * time.Second / time.Duration(blocks), nil
+}
+
+func estimateBlockTime(headNumber uint64, headTime uint64, number uint64, blockTime time.Duration) time.Time {
+	at := time.Unix(int64(headTime), 0)
+	if number <= headNumber {
+		return at
+	}
+	return at.Add(time.Duration(number-headNumber) * blockTime)
+}
+
+// PendingAssertions returns the unconfirmed assertions built on top of
+// confirmed, normally the latest confirmed assertion.
+func (lc *LightClient) PendingAssertions(ctx context.Context, confirmed *ConfirmedAssertion) ([]*PendingAssertion, error) {
+	return lc.ethClient.PendingAssertions(ctx, confirmed.Created)
+}
@@ -0,0 +1,162 @@
+// Package proverapi implements the lightclient_ RPC namespace a nitro node has
+// to serve for the light client to use it as a prover. It only depends on the
+// node's inbox tracker and transaction streamer, so it can be registered on a
+// node with
+//
+//	proverapi.Register(node, genesisBlockNum)
+//
+// once arbnode.CreateNode has built it, before its stack is started.
+package proverapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient"
+)
+
+const Namespace = "lightclient"
+
+var ErrNoBatches = errors.New("no batches posted to the parent chain yet")
+var ErrMessageNotPosted = errors.New("message not yet posted to the parent chain")
+
+// InboxTracker is the part of nitro's arbnode.InboxTracker the API reads batch
+// metadata from.
+type InboxTracker interface {
+	GetBatchCount() (uint64, error)
+	GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error)
+	GetBatchParentChainBlock(seqNum uint64) (uint64, error)
+	FindInboxBatchContainingMessage(pos arbutil.MessageIndex) (uint64, bool, error)
+}
+
+// MessageStreamer is the part of nitro's arbnode.TransactionStreamer the API
+// reads messages from.
+type MessageStreamer interface {
+	GetMessage(pos arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error)
+}
+
+type API struct {
+	inbox           InboxTracker
+	streamer        MessageStreamer
+	seqFilter       *bridgegen.SequencerInboxFilterer
+	genesisBlockNum uint64
+}
+
+// NewAPI creates the API. parentChain is used to look up the transactions
+// that posted batches to sequencerInbox.
+func NewAPI(inbox InboxTracker, streamer MessageStreamer, parentChain bind.ContractFilterer, sequencerInbox common.Address, genesisBlockNum uint64) (*API, error) {
+	seqFilter, err := bridgegen.NewSequencerInboxFilterer(sequencerInbox, parentChain)
+	if err != nil {
+		return nil, err
+	}
+	return &API{
+		inbox:           inbox,
+		streamer:        streamer,
+		seqFilter:       seqFilter,
+		genesisBlockNum: genesisBlockNum,
+	}, nil
+}
+
+// Register creates the API over the inbox tracker, transaction streamer and
+// parent chain reader of n and adds it to the RPC stack of n. The stack must
+// not be started yet.
+func Register(n *arbnode.Node, genesisBlockNum uint64) (*API, error) {
+	if n.L1Reader == nil || n.InboxTracker == nil || n.DeployInfo == nil {
+		return nil, errors.New("node does not follow a parent chain")
+	}
+	api, err := NewAPI(n.InboxTracker, n.TxStreamer, n.L1Reader.Client(), n.DeployInfo.SequencerInbox, genesisBlockNum)
+	if err != nil {
+		return nil, err
+	}
+	n.Stack.RegisterAPIs(api.APIs())
+	return api, nil
+}
+
+func (a *API) APIs() []rpc.API {
+	return []rpc.API{{
+		Namespace: Namespace,
+		Version:   "1.0",
+		Service:   a,
+	}}
+}
+
+// GetLatestIndexL1 returns one past the latest block whose message has been
+// posted to the parent chain.
+func (a *API) GetLatestIndexL1(ctx context.Context) (*lightclient.L1Index, error) {
+	batchCount, err := a.inbox.GetBatchCount()
+	if err != nil {
+		return nil, err
+	}
+	if batchCount == 0 {
+		return nil, ErrNoBatches
+	}
+	messageCount, err := a.inbox.GetBatchMessageCount(batchCount - 1)
+	if err != nil {
+		return nil, err
+	}
+	return &lightclient.L1Index{StateIndex: a.genesisBlockNum + uint64(messageCount)}, nil
+}
+
+// GetL1DataAt returns the message that produced block blockNumber-1 together
+// with the parent chain transaction that posted its batch. The off-by-one
+// matches ArbitrumClient, which asks for block n+1 to get the message of
+// block n.
+func (a *API) GetL1DataAt(ctx context.Context, blockNumber uint64) (*lightclient.MessageTrackingL1Data, error) {
+	if blockNumber <= a.genesisBlockNum {
+		return nil, fmt.Errorf("block %d is not past genesis block %d", blockNumber, a.genesisBlockNum)
+	}
+	pos := arbutil.MessageIndex(blockNumber - 1 - a.genesisBlockNum)
+
+	message, err := a.streamer.GetMessage(pos)
+	if err != nil {
+		return nil, err
+	}
+	if message.Message == nil {
+		return nil, fmt.Errorf("empty message %d", pos)
+	}
+
+	batch, found, err := a.inbox.FindInboxBatchContainingMessage(pos)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: message %d", ErrMessageNotPosted, pos)
+	}
+	parentChainBlock, err := a.inbox.GetBatchParentChainBlock(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := a.seqFilter.FilterSequencerBatchDelivered(&bind.FilterOpts{
+		Start:   parentChainBlock,
+		End:     &parentChainBlock,
+		Context: ctx,
+	}, []*big.Int{new(big.Int).SetUint64(batch)}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	if !iter.Next() {
+		if err := iter.Error(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: batch %d not delivered in parent chain block %d", lightclient.ErrBatchNotFound, batch, parentChainBlock)
+	}
+
+	return &lightclient.MessageTrackingL1Data{
+		Message:      *message.Message,
+		L1TxHash:     iter.Event.Raw.TxHash,
+		DataLocation: iter.Event.DataLocation,
+	}, nil
+}
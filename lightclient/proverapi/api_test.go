@@ -0,0 +1,470 @@
+package proverapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient"
+)
+
+const testGenesisBlockNum = 10
+
+var testSequencerInbox = common.HexToAddress("0x1c479675ad559DC151F6Ec7ed3FbF8ceE79582B6")
+
+type testBatch struct {
+	messageCount     arbutil.MessageIndex
+	parentChainBlock uint64
+	txHash           common.Hash
+	dataLocation     uint8
+}
+
+// testInbox serves batch metadata the way nitro's InboxTracker does, with
+// message counts that include all earlier batches.
+type testInbox struct {
+	batches []testBatch
+}
+
+func (i *testInbox) GetBatchCount() (uint64, error) {
+	return uint64(len(i.batches)), nil
+}
+
+func (i *testInbox) GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error) {
+	if seqNum >= uint64(len(i.batches)) {
+		return 0, fmt.Errorf("no batch %d", seqNum)
+	}
+	return i.batches[seqNum].messageCount, nil
+}
+
+func (i *testInbox) GetBatchParentChainBlock(seqNum uint64) (uint64, error) {
+	if seqNum >= uint64(len(i.batches)) {
+		return 0, fmt.Errorf("no batch %d", seqNum)
+	}
+	return i.batches[seqNum].parentChainBlock, nil
+}
+
+func (i *testInbox) FindInboxBatchContainingMessage(pos arbutil.MessageIndex) (uint64, bool, error) {
+	for seqNum, batch := range i.batches {
+		if pos < batch.messageCount {
+			return uint64(seqNum), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+type testStreamer struct {
+	messages []*arbostypes.MessageWithMetadata
+}
+
+func (s *testStreamer) GetMessage(pos arbutil.MessageIndex) (*arbostypes.MessageWithMetadata, error) {
+	if uint64(pos) >= uint64(len(s.messages)) {
+		return nil, fmt.Errorf("no message %d", pos)
+	}
+	return s.messages[pos], nil
+}
+
+// testParentChain is a parent chain on which the sequencer inbox emitted a
+// SequencerBatchDelivered event for every batch of inbox, in the batch's
+// parent chain block and transaction.
+type testParentChain struct {
+	logs []types.Log
+}
+
+func newTestParentChain(t *testing.T, inbox *testInbox) *testParentChain {
+	t.Helper()
+	seqInboxABI, err := bridgegen.SequencerInboxMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := seqInboxABI.Events["SequencerBatchDelivered"]
+
+	chain := &testParentChain{}
+	for seqNum, batch := range inbox.batches {
+		data, err := event.Inputs.NonIndexed().Pack(
+			common.Hash{},
+			new(big.Int),
+			bridgegen.IBridgeTimeBounds{},
+			batch.dataLocation,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain.logs = append(chain.logs, types.Log{
+			Address: testSequencerInbox,
+			Topics: []common.Hash{
+				event.ID,
+				common.BigToHash(big.NewInt(int64(seqNum))),
+				{},
+				{},
+			},
+			Data:        data,
+			BlockNumber: batch.parentChainBlock,
+			TxHash:      batch.txHash,
+			Index:       uint(seqNum),
+		})
+	}
+	return chain
+}
+
+func (c *testParentChain) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	for _, log := range c.logs {
+		if q.FromBlock != nil && log.BlockNumber < q.FromBlock.Uint64() {
+			continue
+		}
+		if q.ToBlock != nil && log.BlockNumber > q.ToBlock.Uint64() {
+			continue
+		}
+		if len(q.Addresses) > 0 && !containsAddress(q.Addresses, log.Address) {
+			continue
+		}
+		if matchesTopics(q.Topics, log.Topics) {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (c *testParentChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("subscriptions are not supported")
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesTopics(query [][]common.Hash, topics []common.Hash) bool {
+	for i, allowed := range query {
+		if len(allowed) == 0 {
+			continue
+		}
+		if i >= len(topics) {
+			return false
+		}
+		match := false
+		for _, topic := range allowed {
+			if topic == topics[i] {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+// testEth serves the eth_ methods ArbitrumClient.GetLatestState needs next to
+// the lightclient_ namespace.
+type testEth struct {
+	headers []*types.Header
+}
+
+func newTestEth(n int) *testEth {
+	eth := &testEth{}
+	parent := common.Hash{}
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash:  parent,
+			UncleHash:   types.EmptyUncleHash,
+			TxHash:      types.EmptyTxsHash,
+			ReceiptHash: types.EmptyReceiptsHash,
+			Difficulty:  big.NewInt(1),
+			Number:      big.NewInt(int64(i)),
+			GasLimit:    1 << 50,
+			Time:        uint64(i),
+		}
+		eth.headers = append(eth.headers, header)
+		parent = header.Hash()
+	}
+	return eth
+}
+
+func (e *testEth) BlockNumber() (hexutil.Uint64, error) {
+	return hexutil.Uint64(len(e.headers) - 1), nil
+}
+
+func (e *testEth) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (*types.Header, error) {
+	if number < 0 || int(number) >= len(e.headers) {
+		return nil, nil
+	}
+	return e.headers[number], nil
+}
+
+func testMessage(i int) *arbostypes.MessageWithMetadata {
+	requestId := common.BigToHash(big.NewInt(int64(i + 1)))
+	return &arbostypes.MessageWithMetadata{
+		Message: &arbostypes.L1IncomingMessage{
+			Header: &arbostypes.L1IncomingMessageHeader{
+				Kind:        arbostypes.L1MessageType_L2Message,
+				Poster:      common.Address{byte(i + 1)},
+				BlockNumber: uint64(100 + i),
+				Timestamp:   uint64(1000 + i),
+				RequestId:   &requestId,
+				L1BaseFee:   big.NewInt(int64(7 + i)),
+			},
+			L2msg: []byte{0x04, byte(i)},
+		},
+		DelayedMessagesRead: uint64(i),
+	}
+}
+
+// startProver serves the API over inbox and streamer, together with an eth_
+// namespace of ethBlocks blocks, and returns an ArbitrumClient connected to it.
+func startProver(t *testing.T, inbox *testInbox, streamer *testStreamer, ethBlocks int) (*lightclient.ArbitrumClient, *testEth) {
+	t.Helper()
+	api, err := NewAPI(inbox, streamer, newTestParentChain(t, inbox), testSequencerInbox, testGenesisBlockNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeEth := newTestEth(ethBlocks)
+
+	server := rpc.NewServer()
+	for _, service := range api.APIs() {
+		if err := server.RegisterName(service.Namespace, service.Service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := server.RegisterName("eth", fakeEth); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+
+	client, err := lightclient.NewArbitrumClient(context.Background(), httpServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		httpServer.Close()
+		server.Stop()
+	})
+	return client, fakeEth
+}
+
+func testProver() (*testInbox, *testStreamer) {
+	inbox := &testInbox{batches: []testBatch{
+		{messageCount: 2, parentChainBlock: 100, txHash: common.Hash{0xa0}, dataLocation: 0},
+		{messageCount: 5, parentChainBlock: 105, txHash: common.Hash{0xa1}, dataLocation: 3},
+	}}
+	streamer := &testStreamer{}
+	// Messages 5 and 6 are sequenced but not yet posted in a batch
+	for i := 0; i < 7; i++ {
+		streamer.messages = append(streamer.messages, testMessage(i))
+	}
+	return inbox, streamer
+}
+
+func TestGetLatestState(t *testing.T) {
+	tests := []struct {
+		name      string
+		ethBlocks int
+		want      uint64
+	}{
+		// The five posted messages produced genesis block 10 up to block 14
+		{name: "posted head", ethBlocks: 30, want: 14},
+		{name: "node behind the inbox", ethBlocks: 13, want: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inbox, streamer := testProver()
+			client, fakeEth := startProver(t, inbox, streamer, tt.ethBlocks)
+
+			state, err := client.GetLatestState(context.Background(), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.L2BlockNumber != tt.want {
+				t.Errorf("block number %d, want %d", state.L2BlockNumber, tt.want)
+			}
+			if want := fakeEth.headers[tt.want].Hash(); state.L2BlockHash != want {
+				t.Errorf("block hash %s, want %s", state.L2BlockHash, want)
+			}
+		})
+	}
+}
+
+func TestGetLatestStateNoBatches(t *testing.T) {
+	client, _ := startProver(t, &testInbox{}, &testStreamer{}, 30)
+
+	_, err := client.GetLatestState(context.Background(), 0)
+	if err == nil || !strings.Contains(err.Error(), ErrNoBatches.Error()) {
+		t.Fatalf("got error %v, want %v", err, ErrNoBatches)
+	}
+}
+
+func TestGetL1DataAt(t *testing.T) {
+	inbox, streamer := testProver()
+	client, _ := startProver(t, inbox, streamer, 30)
+
+	tests := []struct {
+		blockNumber uint64
+		batch       int
+	}{
+		// Asking for block n returns the message of block n-1, which is
+		// message n-1-genesis
+		{blockNumber: 11, batch: 0},
+		{blockNumber: 12, batch: 0},
+		{blockNumber: 13, batch: 1},
+		{blockNumber: 15, batch: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("block %d", tt.blockNumber), func(t *testing.T) {
+			data, err := client.GetL1DataAt(context.Background(), tt.blockNumber, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			batch := inbox.batches[tt.batch]
+			want := &lightclient.MessageTrackingL1Data{
+				Message:      *streamer.messages[tt.blockNumber-1-testGenesisBlockNum].Message,
+				L1TxHash:     batch.txHash,
+				DataLocation: batch.dataLocation,
+			}
+			// Comparing the whole struct checks that the client decodes every
+			// field the server encodes
+			if !reflect.DeepEqual(data, want) {
+				t.Errorf("got %+v (header %+v), want %+v (header %+v)", data, data.Message.Header, want, want.Message.Header)
+			}
+		})
+	}
+}
+
+func TestGetL1DataAtErrors(t *testing.T) {
+	inbox, streamer := testProver()
+	client, _ := startProver(t, inbox, streamer, 30)
+
+	tests := []struct {
+		name        string
+		blockNumber uint64
+		want        string
+	}{
+		{name: "genesis block", blockNumber: testGenesisBlockNum, want: "is not past genesis block"},
+		{name: "before genesis", blockNumber: 3, want: "is not past genesis block"},
+		{name: "message not posted", blockNumber: 16, want: ErrMessageNotPosted.Error()},
+		{name: "message not sequenced", blockNumber: 18, want: "no message 7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.GetL1DataAt(context.Background(), tt.blockNumber, 0)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetL1DataAtBatchNotDelivered(t *testing.T) {
+	inbox, streamer := testProver()
+	api, err := NewAPI(inbox, streamer, &testParentChain{}, testSequencerInbox, testGenesisBlockNum)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.GetL1DataAt(context.Background(), 11)
+	if !errors.Is(err, lightclient.ErrBatchNotFound) {
+		t.Fatalf("got error %v, want %v", err, lightclient.ErrBatchNotFound)
+	}
+}
+
+// TestNodeServesNamespace registers the API on an in-process node next to a
+// real eth_ service, the way Register does on a nitro node, and calls it over
+// the node's HTTP endpoint.
+func TestNodeServesNamespace(t *testing.T) {
+	stack, err := node.New(&node.Config{
+		HTTPHost:    "127.0.0.1",
+		HTTPPort:    0,
+		HTTPModules: []string{"eth", Namespace},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { stack.Close() })
+
+	ethConfig := ethconfig.Defaults
+	ethConfig.Genesis = &core.Genesis{
+		Config:     params.AllDevChainProtocolChanges,
+		GasLimit:   30_000_000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: big.NewInt(0),
+		Alloc:      types.GenesisAlloc{},
+	}
+	ethService, err := eth.New(stack, &ethConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The node's chain starts at block 0, which the one posted message
+	// produced
+	inbox := &testInbox{batches: []testBatch{
+		{messageCount: 1, parentChainBlock: 100, txHash: common.Hash{0xa0}, dataLocation: 1},
+	}}
+	streamer := &testStreamer{messages: []*arbostypes.MessageWithMetadata{testMessage(0), testMessage(1)}}
+	api, err := NewAPI(inbox, streamer, newTestParentChain(t, inbox), testSequencerInbox, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stack.RegisterAPIs(api.APIs())
+	if err := stack.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := lightclient.NewArbitrumClient(context.Background(), stack.HTTPEndpoint(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	state, err := client.GetLatestState(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis := ethService.BlockChain().Genesis()
+	if state.L2BlockNumber != 0 || state.L2BlockHash != genesis.Hash() {
+		t.Errorf("latest state %d %s, want 0 %s", state.L2BlockNumber, state.L2BlockHash, genesis.Hash())
+	}
+
+	data, err := client.GetL1DataAt(context.Background(), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &lightclient.MessageTrackingL1Data{
+		Message:      *streamer.messages[0].Message,
+		L1TxHash:     inbox.batches[0].txHash,
+		DataLocation: inbox.batches[0].dataLocation,
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("got %+v (header %+v), want %+v (header %+v)", data, data.Message.Header, want, want.Message.Header)
+	}
+
+	if _, err := client.GetL1DataAt(context.Background(), 2, 0); err == nil || !strings.Contains(err.Error(), ErrMessageNotPosted.Error()) {
+		t.Errorf("got error %v, want %v", err, ErrMessageNotPosted)
+	}
+}
@@ -0,0 +1,310 @@
+package lightclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var ErrNoVerifiedHead = errors.New("no verified head yet")
+var ErrUnverifiableBlock = errors.New("block cannot be verified against the tournament head")
+var ErrProofVerification = errors.New("state proof verification failed")
+
+// VerifyingProxy serves a subset of the eth namespace, answering only with
+// data checked against the head that won the tournament or the confirmed
+// anchor it was played from.
+type VerifyingProxy struct {
+	client *LightClient
+	server *rpc.Server
+
+	mu    sync.RWMutex
+	head  *Head
+	chain *HeaderChain // anchored at the confirmed block of head
+}
+
+type verifiedBlockRef struct {
+	header *types.Header
+	prover *ArbitrumClient
+}
+
+func NewVerifyingProxy(client *LightClient) (*VerifyingProxy, error) {
+	proxy := &VerifyingProxy{
+		client: client,
+		server: rpc.NewServer(),
+	}
+	if err := proxy.server.RegisterName("eth", &VerifyingEthAPI{proxy: proxy}); err != nil {
+		return nil, err
+	}
+	return proxy, nil
+}
+
+// SetHead replaces the head the proxy answers against.
+func (p *VerifyingProxy) SetHead(head *Head) {
+	// Without a tournament head there is nothing to fetch headers from
+	chain, _ := p.client.HeaderChain(head)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.head = head
+	p.chain = chain
+}
+
+func (p *VerifyingProxy) headerChain() *HeaderChain {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.chain
+}
+
+func (p *VerifyingProxy) Head() *Head {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.head
+}
+
+// Handler returns the JSON-RPC handler to mount on an HTTP server.
+func (p *VerifyingProxy) Handler() http.Handler {
+	return p.server
+}
+
+func (p *VerifyingProxy) Stop() {
+	p.server.Stop()
+}
+
+// resolveBlock maps a block reference onto one of the verified blocks: the
+// tournament head for latest, pending and safe, the confirmed anchor for
+// finalized, blocks below the anchor linked to it through ParentHash and
+// headers verified in earlier runs kept in the store. Any other block is
+// rejected.
+func (p *VerifyingProxy) resolveBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*verifiedBlockRef, error) {
+	head := p.Head()
+	if head == nil || head.Tournament.Head() == nil {
+		return nil, ErrNoVerifiedHead
+	}
+	survivor := head.Tournament.Head()
+	anchor := head.Anchor
+
+	var wantHash common.Hash
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		wantHash = hash
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		switch number {
+		case rpc.LatestBlockNumber, rpc.PendingBlockNumber, rpc.SafeBlockNumber:
+			wantHash = survivor.State.L2BlockHash
+		case rpc.FinalizedBlockNumber:
+			wantHash = anchor.Header.Hash()
+		case rpc.EarliestBlockNumber:
+			return nil, fmt.Errorf("%w: earliest", ErrUnverifiableBlock)
+		default:
+			switch uint64(number) {
+			case survivor.State.L2BlockNumber:
+				wantHash = survivor.State.L2BlockHash
+			case anchor.Header.Number.Uint64():
+				wantHash = anchor.Header.Hash()
+			default:
+				if chain := p.headerChain(); chain != nil && uint64(number) < anchor.Header.Number.Uint64() {
+					if err := chain.ExtendBack(ctx, uint64(number)); err != nil {
+						return nil, fmt.Errorf("%w: block %d: %w", ErrUnverifiableBlock, number, err)
+					}
+					header, err := chain.Header(ctx, uint64(number))
+					if err != nil {
+						return nil, err
+					}
+					return &verifiedBlockRef{header: header, prover: survivor.Prover}, nil
+				}
+				header, err := p.storedHeader(func(store *Store) (*types.Header, error) { return store.HeaderByNumber(uint64(number)) })
+				if err != nil {
+					return nil, fmt.Errorf("%w: block %d", ErrUnverifiableBlock, number)
+				}
+				return &verifiedBlockRef{header: header, prover: survivor.Prover}, nil
+			}
+		}
+	} else {
+		return nil, fmt.Errorf("%w: invalid block reference", ErrUnverifiableBlock)
+	}
+
+	if wantHash == anchor.Header.Hash() {
+		return &verifiedBlockRef{header: anchor.Header, prover: survivor.Prover}, nil
+	}
+	if wantHash != survivor.State.L2BlockHash {
+		header, err := p.storedHeader(func(store *Store) (*types.Header, error) { return store.HeaderByHash(wantHash) })
+		if err != nil {
+			return nil, fmt.Errorf("%w: block %s", ErrUnverifiableBlock, wantHash.Hex())
+		}
+		return &verifiedBlockRef{header: header, prover: survivor.Prover}, nil
+	}
+
+	block, err := survivor.Prover.GetBlockByHash(ctx, wantHash)
+	if err != nil {
+		return nil, err
+	}
+	if !survivor.Prover.VerifyBlockHash(block.Header(), wantHash) {
+		return nil, fmt.Errorf("%w: header does not hash to %s", ErrUnverifiableBlock, wantHash.Hex())
+	}
+	return &verifiedBlockRef{header: block.Header(), prover: survivor.Prover}, nil
+}
+
+// storedHeader looks a previously verified header up in the store, if any.
+func (p *VerifyingProxy) storedHeader(lookup func(*Store) (*types.Header, error)) (*types.Header, error) {
+	if p.client.store == nil {
+		return nil, ErrNotFound
+	}
+	return lookup(p.client.store)
+}
+
+// verifiedAccount fetches and checks the proof of address and storageKeys
+// against the state root of the referenced block.
+func (p *VerifyingProxy) verifiedAccount(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNrOrHash rpc.BlockNumberOrHash) (*VerifiedAccount, *EthGetProofResult, *verifiedBlockRef, error) {
+	ref, err := p.resolveBlock(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	proof, err := ref.prover.GetProof(ctx, *ref.header.Number, address, hashesToHex(storageKeys))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	account, err := VerifyAccountProof(ref.header, address, storageKeys, proof)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %w", ErrProofVerification, err)
+	}
+	return account, proof, ref, nil
+}
+
+// VerifyingEthAPI is registered under the eth namespace.
+type VerifyingEthAPI struct {
+	proxy *VerifyingProxy
+}
+
+func (api *VerifyingEthAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).SetUint64(api.proxy.client.config.Chain.ID))
+}
+
+func (api *VerifyingEthAPI) BlockNumber() (hexutil.Uint64, error) {
+	head := api.proxy.Head()
+	if head == nil || head.Tournament.Head() == nil {
+		return 0, ErrNoVerifiedHead
+	}
+	return hexutil.Uint64(head.Tournament.Head().State.L2BlockNumber), nil
+}
+
+func (api *VerifyingEthAPI) GetBalance(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	account, _, _, err := api.proxy.verifiedAccount(ctx, address, nil, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(account.Balance), nil
+}
+
+func (api *VerifyingEthAPI) GetTransactionCount(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Uint64, error) {
+	account, _, _, err := api.proxy.verifiedAccount(ctx, address, nil, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	nonce := hexutil.Uint64(account.Nonce)
+	return &nonce, nil
+}
+
+func (api *VerifyingEthAPI) GetCode(ctx context.Context, address common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	account, _, ref, err := api.proxy.verifiedAccount(ctx, address, nil, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	code, err := ref.prover.codeByHash(ctx, address, account.CodeHash, ref.header.Number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProofVerification, err)
+	}
+	return code, nil
+}
+
+func (api *VerifyingEthAPI) GetStorageAt(ctx context.Context, address common.Address, hexKey string, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	key, err := hexutil.Decode(hexKey)
+	if err != nil || len(key) > common.HashLength {
+		return nil, fmt.Errorf("invalid storage key %q", hexKey)
+	}
+	slot := common.BytesToHash(key)
+	account, _, _, err := api.proxy.verifiedAccount(ctx, address, []common.Hash{slot}, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return account.Storage[slot].Bytes(), nil
+}
+
+func (api *VerifyingEthAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNrOrHash rpc.BlockNumberOrHash) (*EthGetProofResult, error) {
+	slots := make([]common.Hash, len(storageKeys))
+	for i, key := range storageKeys {
+		decoded, err := hexutil.Decode(key)
+		if err != nil || len(decoded) > common.HashLength {
+			return nil, fmt.Errorf("invalid storage key %q", key)
+		}
+		slots[i] = common.BytesToHash(decoded)
+	}
+	_, proof, _, err := api.proxy.verifiedAccount(ctx, address, slots, blockNrOrHash)
+	return proof, err
+}
+
+func (api *VerifyingEthAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	ref, err := api.proxy.resolveBlock(ctx, rpc.BlockNumberOrHashWithNumber(number))
+	if err != nil {
+		return nil, err
+	}
+	block, err := ref.prover.GetBlockByHash(ctx, ref.header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if block.Hash() != ref.header.Hash() {
+		return nil, fmt.Errorf("%w: block body served for the wrong header", ErrUnverifiableBlock)
+	}
+	if txHash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); txHash != ref.header.TxHash {
+		return nil, fmt.Errorf("%w: transactions root %s, expected %s", ErrUnverifiableBlock, txHash.Hex(), ref.header.TxHash.Hex())
+	}
+	return marshalVerifiedBlock(block, fullTx, api.proxy.client.config.Chain.ID)
+}
+
+func marshalVerifiedBlock(block *types.Block, fullTx bool, chainId uint64) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(block.Header())
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+
+	fields["size"] = hexutil.Uint64(block.Size())
+	fields["uncles"] = []common.Hash{}
+
+	signer := types.LatestSignerForChainID(new(big.Int).SetUint64(chainId))
+	transactions := make([]interface{}, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if !fullTx {
+			transactions = append(transactions, tx.Hash())
+			continue
+		}
+		encodedTx, err := json.Marshal(tx)
+		if err != nil {
+			return nil, err
+		}
+		txFields := make(map[string]interface{})
+		if err := json.Unmarshal(encodedTx, &txFields); err != nil {
+			return nil, err
+		}
+		txFields["blockHash"] = block.Hash()
+		txFields["blockNumber"] = (*hexutil.Big)(block.Number())
+		txFields["transactionIndex"] = hexutil.Uint64(i)
+		if from, err := types.Sender(signer, tx); err == nil {
+			txFields["from"] = from
+		}
+		transactions = append(transactions, txFields)
+	}
+	fields["transactions"] = transactions
+
+	return fields, nil
+}
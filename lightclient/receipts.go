@@ -0,0 +1,306 @@
+package lightclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrReceiptsRootMismatch = errors.New("receipts do not match the header receipts root")
+var ErrHeaderHashMismatch = errors.New("header does not hash to the trusted block hash")
+var ErrFilterNeedsRange = errors.New("filter must name a block hash or both ends of a block range")
+
+// VerifiedReceipt is a receipt together with its proof against the receipts
+// root of a verified header.
+type VerifiedReceipt struct {
+	Receipt *types.Receipt
+	Proof   *InclusionProof
+}
+
+// VerifiedLog is a log taken from a verified receipt. Proof is the inclusion
+// proof of that receipt.
+type VerifiedLog struct {
+	Log   *types.Log
+	Proof *InclusionProof
+}
+
+// verifiedHeader fetches the header of blockHash, which the caller must trust,
+// for instance because it was confirmed on the parent chain or won the
+// tournament, and checks that it hashes to it.
+func (c *ArbitrumClient) verifiedHeader(ctx context.Context, blockHash common.Hash) (*types.Header, error) {
+	header, err := c.ethClient.HeaderByHash(ctx, blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("header not found: %w", err)
+	}
+	if !c.VerifyBlockHash(header, blockHash) {
+		return nil, fmt.Errorf("%w: %s", ErrHeaderHashMismatch, blockHash.Hex())
+	}
+	return header, nil
+}
+
+// VerifiedReceipts returns all receipts of the block with the trusted hash
+// blockHash, checked against its receipts root. LightClient.VerifiedReceipts
+// checks the block against a verified head first.
+func (c *ArbitrumClient) VerifiedReceipts(ctx context.Context, blockHash common.Hash) ([]*VerifiedReceipt, error) {
+	header, err := c.verifiedHeader(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return c.receiptsForHeader(ctx, header)
+}
+
+// receiptsForHeader fetches the receipts of header and checks them against its
+// receipts root. The root only commits to the type, status, cumulative gas,
+// bloom and logs of each receipt, so the receipts returned are rebuilt from
+// those, the verified transactions of the block and the header. The contract
+// address and effective gas price are committed to by none of them and are
+// left zero.
+func (c *ArbitrumClient) receiptsForHeader(ctx context.Context, header *types.Header) ([]*VerifiedReceipt, error) {
+	blockHash := header.Hash()
+	receipts, err := c.ethClient.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(blockHash, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipts of block %s: %w", blockHash.Hex(), err)
+	}
+
+	receiptsTrie, err := newOrderedTrie(types.Receipts(receipts))
+	if err != nil {
+		return nil, err
+	}
+	if receiptsTrie.root != header.ReceiptHash {
+		return nil, fmt.Errorf("%w: block %s: computed %s, header %s", ErrReceiptsRootMismatch, blockHash.Hex(), receiptsTrie.root.Hex(), header.ReceiptHash.Hex())
+	}
+
+	txs, err := c.transactionsForHeader(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) != len(receipts) {
+		return nil, fmt.Errorf("%w: block %s: %d receipts for %d transactions", ErrReceiptsRootMismatch, blockHash.Hex(), len(receipts), len(txs))
+	}
+
+	verified := make([]*VerifiedReceipt, len(receipts))
+	var cumulativeGasUsed uint64
+	var logIndex uint
+	for i, served := range receipts {
+		if served.CumulativeGasUsed < cumulativeGasUsed {
+			return nil, fmt.Errorf("%w: block %s: cumulative gas of receipt %d decreases", ErrReceiptsRootMismatch, blockHash.Hex(), i)
+		}
+		receipt := &types.Receipt{
+			Type:              served.Type,
+			PostState:         common.CopyBytes(served.PostState),
+			Status:            served.Status,
+			CumulativeGasUsed: served.CumulativeGasUsed,
+			Bloom:             served.Bloom,
+			TxHash:            txs[i].Transaction.Hash(),
+			GasUsed:           served.CumulativeGasUsed - cumulativeGasUsed,
+			BlockHash:         blockHash,
+			BlockNumber:       new(big.Int).Set(header.Number),
+			TransactionIndex:  uint(i),
+		}
+		cumulativeGasUsed = served.CumulativeGasUsed
+
+		receipt.Logs = make([]*types.Log, len(served.Logs))
+		for j, log := range served.Logs {
+			receipt.Logs[j] = &types.Log{
+				Address:     log.Address,
+				Topics:      log.Topics,
+				Data:        log.Data,
+				BlockNumber: header.Number.Uint64(),
+				TxHash:      receipt.TxHash,
+				TxIndex:     uint(i),
+				BlockHash:   blockHash,
+				Index:       logIndex,
+			}
+			logIndex++
+		}
+
+		proof, err := receiptsTrie.prove(uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		verified[i] = &VerifiedReceipt{Receipt: receipt, Proof: proof}
+	}
+	return verified, nil
+}
+
+// VerifiedLogs returns the logs matching filter from the verified receipts of
+// the block filter.BlockHash or of the blocks filter.FromBlock to
+// filter.ToBlock. Every block must be verified by chain. Matching is done
+// locally, so the prover cannot leave logs out.
+func (c *ArbitrumClient) VerifiedLogs(ctx context.Context, chain *HeaderChain, filter ethereum.FilterQuery) ([]*VerifiedLog, error) {
+	var headers []*types.Header
+	if filter.BlockHash != nil {
+		header, err := c.verifiedHeader(ctx, *filter.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if err := chain.Check(header.Number.Uint64(), *filter.BlockHash); err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+	} else {
+		from, to, err := filterRange(filter)
+		if err != nil {
+			return nil, err
+		}
+		for number := from; number <= to; number++ {
+			header, err := chain.Header(ctx, number)
+			if err != nil {
+				return nil, err
+			}
+			headers = append(headers, header)
+		}
+	}
+
+	var logs []*VerifiedLog
+	for _, header := range headers {
+		receipts, err := c.receiptsForHeader(ctx, header)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, filterVerifiedLogs(receipts, filter)...)
+	}
+	return logs, nil
+}
+
+// filterRange returns the block range of a filter without a block hash. Both
+// ends must be explicit block numbers, since tags like latest are resolved by
+// the prover.
+func filterRange(filter ethereum.FilterQuery) (uint64, uint64, error) {
+	if filter.FromBlock == nil || filter.ToBlock == nil || filter.FromBlock.Sign() < 0 || filter.ToBlock.Sign() < 0 || !filter.FromBlock.IsUint64() || !filter.ToBlock.IsUint64() {
+		return 0, 0, ErrFilterNeedsRange
+	}
+	from, to := filter.FromBlock.Uint64(), filter.ToBlock.Uint64()
+	if to < from {
+		return 0, 0, fmt.Errorf("%w: from block %d is past to block %d", ErrFilterNeedsRange, from, to)
+	}
+	return from, to, nil
+}
+
+func filterVerifiedLogs(receipts []*VerifiedReceipt, filter ethereum.FilterQuery) []*VerifiedLog {
+	var logs []*VerifiedLog
+	for _, receipt := range receipts {
+		for _, log := range receipt.Receipt.Logs {
+			if logMatches(log, filter) {
+				logs = append(logs, &VerifiedLog{Log: log, Proof: receipt.Proof})
+			}
+		}
+	}
+	return logs
+}
+
+// logMatches applies the address and topic criteria of filter the same way
+// eth_getLogs does.
+func logMatches(log *types.Log, filter ethereum.FilterQuery) bool {
+	if len(filter.Addresses) > 0 {
+		found := false
+		for _, addr := range filter.Addresses {
+			if addr == log.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(filter.Topics) > len(log.Topics) {
+		return false
+	}
+	for i, alternatives := range filter.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			if bytes.Equal(topic.Bytes(), log.Topics[i].Bytes()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifiedReceipts returns the receipts of the block blockHash, which must be
+// the block of the tournament head, the anchor or one of its ancestors, or a
+// block between the anchor and the tournament head.
+func (lc *LightClient) VerifiedReceipts(ctx context.Context, head *Head, blockHash common.Hash) ([]*VerifiedReceipt, error) {
+	survivor := head.Tournament.Head()
+	if survivor == nil {
+		return nil, ErrNoVerifiedHead
+	}
+	header, err := survivor.Prover.verifiedHeader(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	number := header.Number.Uint64()
+	chain, err := lc.headerChainCovering(ctx, head, number, number)
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.Check(number, blockHash); err != nil {
+		return nil, err
+	}
+	return survivor.Prover.receiptsForHeader(ctx, header)
+}
+
+// VerifiedLogs returns the logs matching filter, whose blocks must be verified
+// from head the same way as for VerifiedReceipts.
+func (lc *LightClient) VerifiedLogs(ctx context.Context, head *Head, filter ethereum.FilterQuery) ([]*VerifiedLog, error) {
+	survivor := head.Tournament.Head()
+	if survivor == nil {
+		return nil, ErrNoVerifiedHead
+	}
+	var from, to uint64
+	if filter.BlockHash != nil {
+		header, err := survivor.Prover.verifiedHeader(ctx, *filter.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		from, to = header.Number.Uint64(), header.Number.Uint64()
+	} else {
+		var err error
+		from, to, err = filterRange(filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+	chain, err := lc.headerChainCovering(ctx, head, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return survivor.Prover.VerifiedLogs(ctx, chain, filter)
+}
+
+// headerChainCovering returns the header chain of head verified from from to
+// to, walking back from the anchor and forward to the tournament head.
+func (lc *LightClient) headerChainCovering(ctx context.Context, head *Head, from uint64, to uint64) (*HeaderChain, error) {
+	chain, err := lc.HeaderChain(head)
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.ExtendBack(ctx, from); err != nil {
+		return nil, err
+	}
+	if _, highest := chain.Range(); to > highest {
+		survivor := head.Tournament.Head()
+		if to > survivor.State.L2BlockNumber {
+			return nil, fmt.Errorf("%w: block %d is past the tournament head %d", ErrOutOfRange, to, survivor.State.L2BlockNumber)
+		}
+		if err := chain.ExtendForward(ctx, survivor.State.L2BlockNumber, survivor.State.L2BlockHash); err != nil {
+			return nil, err
+		}
+	}
+	return chain, nil
+}
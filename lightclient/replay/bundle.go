@@ -0,0 +1,91 @@
+// Package replay records the HTTP traffic of a run, JSON-RPC calls to the
+// parent chain and the provers as well as beacon API requests for blobs, into
+// a bundle that can be replayed later without network access.
+//
+// The Recorder and Replayer are http.RoundTrippers, which lightclient.Config
+// takes as its Transport. Only HTTP endpoints can be recorded; WebSocket and
+// IPC connections do not go through a transport.
+//
+// Endpoints are identified by scheme, host and a hash of their path and query,
+// so endpoints sharing a host stay apart while API keys embedded in URLs are
+// not written to the bundle. Beacon requests keep the API path starting at
+// /eth/ in the clear.
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/fakeprover"
+)
+
+// Bundle is a recording of a run.
+type Bundle struct {
+	RPC  map[string][]fakeprover.Fixture `json:"rpc"`
+	HTTP []HTTPExchange                  `json:"http"`
+}
+
+// HTTPExchange is a recorded plain HTTP request, such as a beacon API call.
+type HTTPExchange struct {
+	Method string `json:"method"`
+	Key    string `json:"key"`
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+}
+
+func NewBundle() *Bundle {
+	return &Bundle{RPC: make(map[string][]fakeprover.Fixture)}
+}
+
+func LoadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	bundle := NewBundle()
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", path, err)
+	}
+	return bundle, nil
+}
+
+func (b *Bundle) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// endpointKey identifies the JSON-RPC endpoint at u. The path and query are
+// hashed, since they often carry API keys.
+func endpointKey(u *url.URL) string {
+	key := u.Scheme + "://" + u.Host
+	rest := strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		rest += "?" + u.RawQuery
+	}
+	if rest == "" {
+		return key
+	}
+	sum := sha256.Sum256([]byte(rest))
+	return key + "/" + hex.EncodeToString(sum[:8])
+}
+
+// requestKey identifies a plain HTTP request: the endpoint it is sent to,
+// keyed like a JSON-RPC endpoint, followed by the beacon API path.
+func requestKey(u *url.URL) string {
+	endpoint := *u
+	apiPath := ""
+	if i := strings.Index(u.Path, "/eth/"); i >= 0 {
+		endpoint.Path = u.Path[:i]
+		endpoint.RawPath = ""
+		apiPath = u.Path[i:]
+	}
+	return endpointKey(&endpoint) + apiPath
+}
@@ -0,0 +1,220 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/jakovmitrovski/arbitrum-light-client-go/lightclient/fakeprover"
+)
+
+type rpcMessage struct {
+	JSONRPC string                   `json:"jsonrpc"`
+	ID      json.RawMessage          `json:"id"`
+	Method  string                   `json:"method,omitempty"`
+	Params  json.RawMessage          `json:"params,omitempty"`
+	Result  json.RawMessage          `json:"result,omitempty"`
+	Error   *fakeprover.FixtureError `json:"error,omitempty"`
+}
+
+// parseRPC decodes a single or batched JSON-RPC message. It returns false if
+// body is not JSON-RPC.
+func parseRPC(body []byte) ([]rpcMessage, bool) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, false
+	}
+	var messages []rpcMessage
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &messages); err != nil {
+			return nil, false
+		}
+	} else {
+		var message rpcMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			return nil, false
+		}
+		messages = []rpcMessage{message}
+	}
+	for _, message := range messages {
+		if message.JSONRPC != "2.0" {
+			return nil, false
+		}
+	}
+	return messages, true
+}
+
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// Recorder is an http.RoundTripper adding every exchange it forwards to a
+// bundle.
+type Recorder struct {
+	next http.RoundTripper
+
+	mu     sync.Mutex
+	bundle *Bundle
+}
+
+func NewRecorder(next http.RoundTripper) *Recorder {
+	return &Recorder{next: next, bundle: NewBundle()}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	forwarded := req.Clone(req.Context())
+	forwarded.Body = io.NopCloser(bytes.NewReader(reqBody))
+
+	resp, err := r.next.RoundTrip(forwarded)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if requests, ok := parseRPC(reqBody); ok {
+		if responses, ok := parseRPC(respBody); ok {
+			r.recordRPC(endpointKey(req.URL), requests, responses)
+			return resp, nil
+		}
+	}
+	r.bundle.HTTP = append(r.bundle.HTTP, HTTPExchange{
+		Method: req.Method,
+		Key:    requestKey(req.URL),
+		Status: resp.StatusCode,
+		Body:   string(respBody),
+	})
+	return resp, nil
+}
+
+func (r *Recorder) recordRPC(endpoint string, requests []rpcMessage, responses []rpcMessage) {
+	byID := make(map[string]rpcMessage, len(responses))
+	for _, response := range responses {
+		byID[string(response.ID)] = response
+	}
+	for _, request := range requests {
+		response, ok := byID[string(request.ID)]
+		if !ok {
+			continue
+		}
+		r.bundle.RPC[endpoint] = append(r.bundle.RPC[endpoint], fakeprover.Fixture{
+			Method: request.Method,
+			Params: request.Params,
+			Result: response.Result,
+			Error:  response.Error,
+		})
+	}
+}
+
+// Bundle returns the recording so far.
+func (r *Recorder) Bundle() *Bundle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bundle := NewBundle()
+	for endpoint, fixtures := range r.bundle.RPC {
+		bundle.RPC[endpoint] = append([]fakeprover.Fixture(nil), fixtures...)
+	}
+	bundle.HTTP = append([]HTTPExchange(nil), r.bundle.HTTP...)
+	return bundle
+}
+
+// Replayer is an http.RoundTripper answering from a bundle. A request recorded
+// several times is answered with the recorded responses in order.
+type Replayer struct {
+	servers map[string]*fakeprover.Server
+
+	mu     sync.Mutex
+	http   map[string][]HTTPExchange
+	served map[string]int
+}
+
+func NewReplayer(bundle *Bundle) (*Replayer, error) {
+	r := &Replayer{
+		servers: make(map[string]*fakeprover.Server, len(bundle.RPC)),
+		http:    make(map[string][]HTTPExchange),
+		served:  make(map[string]int),
+	}
+	for endpoint, fixtures := range bundle.RPC {
+		server, err := fakeprover.New(fixtures)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", endpoint, err)
+		}
+		r.servers[endpoint] = server
+	}
+	for _, exchange := range bundle.HTTP {
+		key := exchange.Method + " " + exchange.Key
+		r.http[key] = append(r.http[key], exchange)
+	}
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := parseRPC(body); ok {
+		server, ok := r.servers[endpointKey(req.URL)]
+		if !ok {
+			return nil, fmt.Errorf("replay: no recorded JSON-RPC endpoint %s", endpointKey(req.URL))
+		}
+		replayed := req.Clone(req.Context())
+		replayed.Body = io.NopCloser(bytes.NewReader(body))
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, replayed)
+		resp := recorder.Result()
+		resp.Request = req
+		return resp, nil
+	}
+
+	exchange, ok := r.nextHTTP(req.Method + " " + requestKey(req.URL))
+	if !ok {
+		return nil, fmt.Errorf("replay: no recorded response for %s %s", req.Method, requestKey(req.URL))
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader([]byte(exchange.Body))),
+		ContentLength: int64(len(exchange.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Replayer) nextHTTP(key string) (HTTPExchange, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exchanges, ok := r.http[key]
+	if !ok {
+		return HTTPExchange{}, false
+	}
+	i := r.served[key]
+	if i < len(exchanges)-1 {
+		r.served[key] = i + 1
+	}
+	return exchanges[min(i, len(exchanges)-1)], true
+}
@@ -0,0 +1,216 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var ErrProofEncoding = errors.New("malformed proof")
+var ErrProofRequestMismatch = errors.New("proof does not cover the requested address and slots")
+var ErrAccountProof = errors.New("account proof does not verify against the state root")
+var ErrAccountMismatch = errors.New("account fields differ from the proven account")
+var ErrStorageRootMismatch = errors.New("storage hash differs from the storage root proven in the account")
+var ErrStorageProof = errors.New("storage proof does not verify against the storage root")
+var ErrStorageMismatch = errors.New("storage value differs from the proven value")
+
+// StateProofError attributes a proof that failed verification to the prover
+// that served it.
+type StateProofError struct {
+	Prover  string
+	Account common.Address
+	Err     error
+}
+
+func (e *StateProofError) Error() string {
+	return fmt.Sprintf("prover %s served a bad proof for account %s: %v", e.Prover, e.Account.Hex(), e.Err)
+}
+
+func (e *StateProofError) Unwrap() error {
+	return e.Err
+}
+
+// VerifiedAccount is an account, and the requested storage slots of it, proven
+// against the state root of a block.
+type VerifiedAccount struct {
+	Address     common.Address
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Exists      bool
+	Nonce       uint64
+	Balance     *big.Int
+	StorageRoot common.Hash
+	CodeHash    common.Hash
+	Storage     map[common.Hash]common.Hash
+}
+
+// VerifyAccount fetches the proof of addr and slots at header from the prover
+// and verifies it against header.Root.
+func (c *ArbitrumClient) VerifyAccount(ctx context.Context, addr common.Address, slots []common.Hash, header *types.Header) (*VerifiedAccount, error) {
+	proof, err := c.GetProof(ctx, *header.Number, addr, hashesToHex(slots))
+	if err != nil {
+		return nil, err
+	}
+	return VerifyAccountProof(header, addr, slots, proof)
+}
+
+// VerifyAccountProof checks an eth_getProof response for addr and slots
+// against header.Root. The storage proofs are checked against the storage
+// root taken from the proven account leaf, never against the storage hash the
+// prover reports, so another storage trie cannot be substituted.
+func VerifyAccountProof(header *types.Header, addr common.Address, slots []common.Hash, proof *EthGetProofResult) (*VerifiedAccount, error) {
+	if common.HexToAddress(proof.Address) != addr || len(proof.StorageProofs) != len(slots) {
+		return nil, fmt.Errorf("%w: account %s", ErrProofRequestMismatch, addr.Hex())
+	}
+
+	proofDB, err := proofNodes(proof.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("%w: account %s: %v", ErrProofEncoding, addr.Hex(), err)
+	}
+	leaf, err := trie.VerifyProof(header.Root, crypto.Keccak256(addr.Bytes()), proofDB)
+	if err != nil {
+		return nil, fmt.Errorf("%w: account %s at block %d: %v", ErrAccountProof, addr.Hex(), header.Number.Uint64(), err)
+	}
+
+	account := &VerifiedAccount{
+		Address:     addr,
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash(),
+		Balance:     new(big.Int),
+		StorageRoot: types.EmptyRootHash,
+		CodeHash:    types.EmptyCodeHash,
+		Storage:     make(map[common.Hash]common.Hash, len(slots)),
+	}
+	if len(leaf) > 0 {
+		var state types.StateAccount
+		if err := rlp.DecodeBytes(leaf, &state); err != nil {
+			return nil, fmt.Errorf("%w: account %s leaf: %v", ErrProofEncoding, addr.Hex(), err)
+		}
+		account.Exists = true
+		account.Nonce = state.Nonce
+		account.Balance = state.Balance.ToBig()
+		account.StorageRoot = state.Root
+		account.CodeHash = common.BytesToHash(state.CodeHash)
+	}
+
+	if err := checkAccountFields(account, proof); err != nil {
+		return nil, err
+	}
+
+	for i, slot := range slots {
+		storageProof := proof.StorageProofs[i]
+		if common.HexToHash(storageProof.Key) != slot {
+			return nil, fmt.Errorf("%w: account %s slot %s", ErrProofRequestMismatch, addr.Hex(), slot.Hex())
+		}
+		value, err := verifyStorageSlot(account.StorageRoot, slot, storageProof.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("account %s slot %s: %w", addr.Hex(), slot.Hex(), err)
+		}
+		claimed, err := hexutil.DecodeBig(canonicalHexQuantity(storageProof.Value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: account %s slot %s value: %v", ErrProofEncoding, addr.Hex(), slot.Hex(), err)
+		}
+		if common.BigToHash(claimed) != value {
+			return nil, fmt.Errorf("%w: account %s slot %s: claimed %s, proven %s", ErrStorageMismatch, addr.Hex(), slot.Hex(), common.BigToHash(claimed).Hex(), value.Hex())
+		}
+		account.Storage[slot] = value
+	}
+
+	return account, nil
+}
+
+// checkAccountFields compares the fields the prover reported with the proven
+// account. Absent accounts may be reported with zero hashes.
+func checkAccountFields(account *VerifiedAccount, proof *EthGetProofResult) error {
+	nonce, err := hexutil.DecodeUint64(canonicalHexQuantity(proof.Nonce))
+	if err != nil {
+		return fmt.Errorf("%w: account %s nonce: %v", ErrProofEncoding, account.Address.Hex(), err)
+	}
+	balance, err := hexutil.DecodeBig(canonicalHexQuantity(proof.Balance))
+	if err != nil {
+		return fmt.Errorf("%w: account %s balance: %v", ErrProofEncoding, account.Address.Hex(), err)
+	}
+	if nonce != account.Nonce {
+		return fmt.Errorf("%w: account %s nonce: claimed %d, proven %d", ErrAccountMismatch, account.Address.Hex(), nonce, account.Nonce)
+	}
+	if balance.Cmp(account.Balance) != 0 {
+		return fmt.Errorf("%w: account %s balance: claimed %s, proven %s", ErrAccountMismatch, account.Address.Hex(), balance, account.Balance)
+	}
+
+	codeHash := common.HexToHash(proof.CodeHash)
+	if codeHash != account.CodeHash && !(!account.Exists && codeHash == common.Hash{}) {
+		return fmt.Errorf("%w: account %s code hash: claimed %s, proven %s", ErrAccountMismatch, account.Address.Hex(), codeHash.Hex(), account.CodeHash.Hex())
+	}
+	storageHash := common.HexToHash(proof.StorageHash)
+	if storageHash != account.StorageRoot && !(!account.Exists && storageHash == common.Hash{}) {
+		return fmt.Errorf("%w: account %s: claimed %s, proven %s", ErrStorageRootMismatch, account.Address.Hex(), storageHash.Hex(), account.StorageRoot.Hex())
+	}
+	return nil
+}
+
+// verifyStorageSlot returns the value of slot proven against storageRoot.
+func verifyStorageSlot(storageRoot common.Hash, slot common.Hash, nodes []string) (common.Hash, error) {
+	if storageRoot == types.EmptyRootHash {
+		return common.Hash{}, nil
+	}
+	proofDB, err := proofNodes(nodes)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: %v", ErrProofEncoding, err)
+	}
+	leaf, err := trie.VerifyProof(storageRoot, crypto.Keccak256(slot.Bytes()), proofDB)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: %v", ErrStorageProof, err)
+	}
+	if len(leaf) == 0 {
+		return common.Hash{}, nil
+	}
+	_, content, _, err := rlp.Split(leaf)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: storage leaf: %v", ErrProofEncoding, err)
+	}
+	return common.BytesToHash(content), nil
+}
+
+func hashesToHex(hashes []common.Hash) []string {
+	hexes := make([]string, len(hashes))
+	for i, hash := range hashes {
+		hexes[i] = hash.Hex()
+	}
+	return hexes
+}
+
+func proofNodes(nodes []string) (*memorydb.Database, error) {
+	db := memorydb.New()
+	for _, encoded := range nodes {
+		node, err := hexutil.Decode(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// canonicalHexQuantity strips leading zeros some nodes leave in quantities,
+// which hexutil rejects.
+func canonicalHexQuantity(s string) string {
+	digits := trimHexPrefix(s)
+	for len(digits) > 1 && digits[0] == '0' {
+		digits = digits[1:]
+	}
+	if digits == "" {
+		digits = "0"
+	}
+	return "0x" + digits
+}
@@ -0,0 +1,307 @@
+package lightclient
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	flag "github.com/spf13/pflag"
+)
+
+var ErrNotFound = errors.New("not found in store")
+
+type StoreConfig struct {
+	Dir string `koanf:"dir"`
+}
+
+var DefaultStoreConfig = StoreConfig{
+	Dir: "",
+}
+
+func StoreConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".dir", DefaultStoreConfig.Dir, "directory of the pebble database keeping verified data across runs (empty disables)")
+}
+
+// Key layout of the store:
+//
+//	latestAssertionKey            -> hash of the last stored confirmed assertion
+//	assertionPrefix + hash        -> JSON encoded StoredAssertion
+//	headerNumberPrefix + hash     -> big endian block number
+//	headerPrefix + number + hash  -> RLP encoded header
+//	tournamentPrefix + unix nanos -> JSON encoded TournamentRecord
+//	batchPrefix + seqNum          -> JSON encoded CachedBatch
+//	batchTxPrefix + tx hash       -> big endian batch sequence number
+var (
+	latestAssertionKey = []byte("LatestAssertion")
+	assertionPrefix    = []byte("a")
+	headerPrefix       = []byte("h")
+	headerNumberPrefix = []byte("H")
+	tournamentPrefix   = []byte("t")
+	batchPrefix        = []byte("b")
+	batchTxPrefix      = []byte("B")
+)
+
+// StoredAssertion keeps the raw events of a confirmed assertion, so it can be
+// decoded again with the RollupCore bindings without scanning parent chain logs.
+type StoredAssertion struct {
+	Hash      common.Hash `json:"hash"`
+	Confirmed types.Log   `json:"confirmed"`
+	Created   types.Log   `json:"created"`
+}
+
+type TournamentRecordSurvivor struct {
+	Prover      string      `json:"prover"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+}
+
+// TournamentRecord is the outcome of a tournament played from an anchor.
+type TournamentRecord struct {
+	Time            time.Time                  `json:"time"`
+	AnchorAssertion common.Hash                `json:"anchorAssertion"`
+	AnchorNumber    uint64                     `json:"anchorNumber"`
+	AnchorHash      common.Hash                `json:"anchorHash"`
+	At              uint64                     `json:"at,omitempty"`
+	Survivors       []TournamentRecordSurvivor `json:"survivors"`
+}
+
+// Store persists verified assertions, headers, tournament outcomes and
+// decoded batches.
+type Store struct {
+	db *pebble.DB
+}
+
+func OpenStore(dir string) (*Store, error) {
+	db, err := pebble.Open(dir, &pebble.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store at %s: %w", dir, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) get(key []byte) ([]byte, error) {
+	value, closer, err := s.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return common.CopyBytes(value), nil
+}
+
+func prefixedKey(prefix []byte, parts ...[]byte) []byte {
+	key := common.CopyBytes(prefix)
+	for _, part := range parts {
+		key = append(key, part...)
+	}
+	return key
+}
+
+func encodeUint64(n uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	return buf[:]
+}
+
+// PutAssertion stores a confirmed assertion and marks it as the latest one.
+func (s *Store) PutAssertion(assertion *ConfirmedAssertion) error {
+	encoded, err := json.Marshal(StoredAssertion{
+		Hash:      assertion.Hash,
+		Confirmed: assertion.Confirmed.Raw,
+		Created:   assertion.Created.Raw,
+	})
+	if err != nil {
+		return err
+	}
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	if err := batch.Set(prefixedKey(assertionPrefix, assertion.Hash.Bytes()), encoded, nil); err != nil {
+		return err
+	}
+	if err := batch.Set(latestAssertionKey, assertion.Hash.Bytes(), nil); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+func (s *Store) Assertion(hash common.Hash) (*StoredAssertion, error) {
+	encoded, err := s.get(prefixedKey(assertionPrefix, hash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	var stored StoredAssertion
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		return nil, fmt.Errorf("corrupted assertion %s: %w", hash.Hex(), err)
+	}
+	return &stored, nil
+}
+
+// LatestAssertion returns the hash of the last assertion stored, the point a
+// restarted client resumes from.
+func (s *Store) LatestAssertion() (common.Hash, error) {
+	encoded, err := s.get(latestAssertionKey)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(encoded), nil
+}
+
+// PutHeader stores a header that was checked against a trusted block hash.
+func (s *Store) PutHeader(header *types.Header) error {
+	return s.PutHeaders([]*types.Header{header})
+}
+
+// PutHeaders stores headers that were checked against trusted block hashes,
+// such as the ones a HeaderChain verified, in a single write.
+func (s *Store) PutHeaders(headers []*types.Header) error {
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	for _, header := range headers {
+		encoded, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			return err
+		}
+		hash := header.Hash()
+		number := encodeUint64(header.Number.Uint64())
+		if err := batch.Set(prefixedKey(headerPrefix, number, hash.Bytes()), encoded, nil); err != nil {
+			return err
+		}
+		if err := batch.Set(prefixedKey(headerNumberPrefix, hash.Bytes()), number, nil); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+func (s *Store) HeaderByHash(hash common.Hash) (*types.Header, error) {
+	number, err := s.get(prefixedKey(headerNumberPrefix, hash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := s.get(prefixedKey(headerPrefix, number, hash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	return decodeHeader(encoded)
+}
+
+func (s *Store) HeaderByNumber(number uint64) (*types.Header, error) {
+	prefix := prefixedKey(headerPrefix, encodeUint64(number))
+	iter, err := s.db.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: prefixedKey(headerPrefix, encodeUint64(number+1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	if !iter.First() {
+		return nil, ErrNotFound
+	}
+	return decodeHeader(iter.Value())
+}
+
+func decodeHeader(encoded []byte) (*types.Header, error) {
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(encoded, header); err != nil {
+		return nil, fmt.Errorf("corrupted header: %w", err)
+	}
+	return header, nil
+}
+
+func (s *Store) PutTournament(record *TournamentRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	key := prefixedKey(tournamentPrefix, encodeUint64(uint64(record.Time.UnixNano())))
+	return s.db.Set(key, encoded, pebble.Sync)
+}
+
+// Tournaments returns up to limit stored tournament outcomes, newest first.
+// A limit of 0 returns all of them.
+func (s *Store) Tournaments(limit int) ([]*TournamentRecord, error) {
+	iter, err := s.db.NewIter(&pebble.IterOptions{
+		LowerBound: tournamentPrefix,
+		UpperBound: prefixUpperBound(tournamentPrefix),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var records []*TournamentRecord
+	for valid := iter.Last(); valid && (limit == 0 || len(records) < limit); valid = iter.Prev() {
+		record := new(TournamentRecord)
+		if err := json.Unmarshal(iter.Value(), record); err != nil {
+			return nil, fmt.Errorf("corrupted tournament record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// PutBatch stores a decoded batch together with the index of its submission
+// transaction.
+func (s *Store) PutBatch(cached *CachedBatch) error {
+	encoded, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	seqNum := encodeUint64(cached.SequenceNumber)
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	if err := batch.Set(prefixedKey(batchPrefix, seqNum), encoded, nil); err != nil {
+		return err
+	}
+	if err := batch.Set(prefixedKey(batchTxPrefix, cached.SubmissionTx.Bytes()), seqNum, nil); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+func (s *Store) Batch(seqNum uint64) (*CachedBatch, error) {
+	encoded, err := s.get(prefixedKey(batchPrefix, encodeUint64(seqNum)))
+	if err != nil {
+		return nil, err
+	}
+	cached := new(CachedBatch)
+	if err := json.Unmarshal(encoded, cached); err != nil {
+		return nil, fmt.Errorf("corrupted batch %d: %w", seqNum, err)
+	}
+	return cached, nil
+}
+
+// BatchBySubmission returns the sequence number of the batch posted in the
+// parent chain transaction txHash.
+func (s *Store) BatchBySubmission(txHash common.Hash) (uint64, error) {
+	encoded, err := s.get(prefixedKey(batchTxPrefix, txHash.Bytes()))
+	if err != nil {
+		return 0, err
+	}
+	if len(encoded) != 8 {
+		return 0, fmt.Errorf("corrupted batch index for %s", txHash.Hex())
+	}
+	return binary.BigEndian.Uint64(encoded), nil
+}
+
+func prefixUpperBound(prefix []byte) []byte {
+	upper := common.CopyBytes(prefix)
+	upper[len(upper)-1]++
+	return upper
+}
@@ -0,0 +1,308 @@
+package lightclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+)
+
+type ChallengeResult int
+
+const (
+	BothLose ChallengeResult = iota
+	LargestLosesParticipantWins
+	LargestWinsParticipantLoses
+	BothWin
+)
+
+func (r ChallengeResult) String() string {
+	switch r {
+	case BothLose:
+		return "both-lose"
+	case LargestLosesParticipantWins:
+		return "largest-loses-participant-wins"
+	case LargestWinsParticipantLoses:
+		return "largest-wins-participant-loses"
+	case BothWin:
+		return "both-win"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(r))
+	}
+}
+
+// TournamentSurvivor is a prover that was not eliminated, together with the
+// head it claimed when the tournament started.
+type TournamentSurvivor struct {
+	Prover *ArbitrumClient
+	State  MessageTrackingL2Data
+}
+
+type TournamentResult struct {
+	Survivors []TournamentSurvivor
+}
+
+// Head returns the survivor with the highest claimed block, or nil if no prover survived.
+func (r *TournamentResult) Head() *TournamentSurvivor {
+	if len(r.Survivors) == 0 {
+		return nil
+	}
+	return &r.Survivors[0]
+}
+
+// VerifyOracles runs the consensus and the execution oracle for block index of the given prover.
+func VerifyOracles(ctx context.Context, arbClient *ArbitrumClient, index uint64, config *Config) (bool, error) {
+	if config.Timeouts.Oracle > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeouts.Oracle)
+		defer cancel()
+	}
+	arbChainId := config.Chain.ID
+
+	prevBlock, err := arbClient.GetBlockByNumber(ctx, big.NewInt(int64(index-1)))
+	if err != nil {
+		return false, err
+	}
+	currBlock, err := arbClient.GetBlockByNumber(ctx, big.NewInt(int64(index)))
+	if err != nil {
+		return false, err
+	}
+
+	prevTrackingL1Data, err := arbClient.GetL1DataAt(ctx, index, arbChainId)
+	if err != nil {
+		return false, err
+	}
+	currTrackingL1Data, err := arbClient.GetL1DataAt(ctx, index+1, arbChainId)
+	if err != nil {
+		return false, err
+	}
+
+	consensusOracleResult, err := ExecuteConsensusOracle(ctx, *prevTrackingL1Data, *currTrackingL1Data, config, arbClient.parentChain, arbClient.dasReader, arbClient.batchCache)
+	if err != nil || !consensusOracleResult {
+		return false, err
+	}
+
+	return executeBlock(ctx, arbClient, prevBlock.Header(), currBlock.Header(), prevTrackingL1Data, currTrackingL1Data, arbChainId)
+}
+
+// executeBlock runs the execution oracle for currHeader, given the messages
+// the prover reports at its index and the next one. The first block after
+// genesis is executed from the Initialize message, with the following message
+// on top, rather than from the next message alone.
+func executeBlock(ctx context.Context, arbClient *ArbitrumClient, prevHeader *types.Header, currHeader *types.Header, prevL1Data *MessageTrackingL1Data, currL1Data *MessageTrackingL1Data, chainId uint64) (bool, error) {
+	if prevL1Data.Message.Header.Kind == arbostypes.L1MessageType_Initialize {
+		return ExecuteExecutionOracle(ctx, arbClient, prevHeader, &prevL1Data.Message, currHeader, chainId, &currL1Data.Message)
+	}
+	return ExecuteExecutionOracle(ctx, arbClient, prevHeader, &currL1Data.Message, currHeader, chainId)
+}
+
+// Tournament pits the provers against each other starting from the verified
+// neonGenesisBlock. With n == 0 every prover is asked for its latest state,
+// otherwise all provers are compared at block n.
+func Tournament(ctx context.Context, neonGenesisBlock types.Header, provers []*ArbitrumClient, config *Config, n uint64) (*TournamentResult, error) {
+	if len(provers) == 0 {
+		return nil, fmt.Errorf("no provers to run the tournament with")
+	}
+	if config.Timeouts.Tournament > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeouts.Tournament)
+		defer cancel()
+	}
+	arbChainId := config.Chain.ID
+
+	arbClients := make([]*ArbitrumClient, len(provers))
+	copy(arbClients, provers)
+
+	sizes := make(map[*ArbitrumClient]MessageTrackingL2Data)
+
+	for i := 0; i < len(arbClients); i++ {
+		var state *MessageTrackingL2Data
+		var err error
+		if n == 0 {
+			state, err = arbClients[i].GetLatestState(ctx, arbChainId)
+		} else {
+			state, err = arbClients[i].GetStateAt(ctx, n, arbChainId)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get state of prover %s: %w", arbClients[i].URL(), err)
+		}
+		sizes[arbClients[i]] = *state
+	}
+
+	sort.Slice(arbClients, func(i, j int) bool {
+		return sizes[arbClients[i]].L2BlockNumber > sizes[arbClients[j]].L2BlockNumber
+	})
+
+	S := make(map[*ArbitrumClient]bool)
+	S[arbClients[0]] = true
+
+	largest := arbClients[0]
+
+	for i := 1; i < len(arbClients); i++ {
+		participant := arbClients[i]
+
+		for {
+			result := Challenge(neonGenesisBlock, largest, sizes[largest], participant, sizes[participant], ctx, config)
+
+			if result == BothWin {
+				S[participant] = true
+				break
+			} else if result == BothLose || result == LargestLosesParticipantWins {
+				delete(S, largest)
+
+				var newLargest *ArbitrumClient
+				var maxSize uint64
+				for survivor := range S {
+					if sizes[survivor].L2BlockNumber > maxSize {
+						maxSize = sizes[survivor].L2BlockNumber
+						newLargest = survivor
+					}
+				}
+				largest = newLargest
+
+				if largest != nil {
+					continue
+				} else {
+					break
+				}
+			} else {
+				break
+			}
+		}
+
+		if len(S) == 0 {
+			S[participant] = true
+			largest = participant
+		}
+	}
+
+	result := &TournamentResult{}
+	for survivor := range S {
+		result.Survivors = append(result.Survivors, TournamentSurvivor{Prover: survivor, State: sizes[survivor]})
+	}
+	sort.Slice(result.Survivors, func(i, j int) bool {
+		return result.Survivors[i].State.L2BlockNumber > result.Survivors[j].State.L2BlockNumber
+	})
+
+	return result, nil
+}
+
+func Challenge(neonGenesisBlock types.Header, largest *ArbitrumClient, largestState MessageTrackingL2Data, participant *ArbitrumClient, participantState MessageTrackingL2Data, ctx context.Context, config *Config) ChallengeResult {
+	log.Debug("challenge", "largest", largest.URL(), "largestSize", largestState.L2BlockNumber, "participant", participant.URL(), "participantSize", participantState.L2BlockNumber)
+
+	largestStateLower, err := largest.GetBlockByNumber(ctx, big.NewInt(int64(participantState.L2BlockNumber)))
+	if err != nil {
+		log.Warn("largest prover failed to get block", "prover", largest.URL(), "block", participantState.L2BlockNumber, "err", err)
+		return LargestLosesParticipantWins
+	}
+
+	if largestStateLower.Header().Hash() == participantState.L2BlockHash {
+		// If they agree on common prefix, participant is not losing
+		// Now test the remaining blocks of the larger client
+		log.Debug("provers agree on common prefix", "from", participantState.L2BlockNumber+1, "to", largestState.L2BlockNumber)
+
+		for index := participantState.L2BlockNumber + 1; index < min(largestState.L2BlockNumber, participantState.L2BlockNumber+config.Trust.ChallengeWindow); index++ {
+			result, err := VerifyOracles(ctx, largest, index, config)
+			if err != nil {
+				log.Warn("oracle verification failed", "prover", largest.URL(), "block", index, "err", err)
+			}
+			if !result {
+				return LargestLosesParticipantWins
+			}
+		}
+
+		return BothWin
+
+	} else {
+		log.Debug("disagreement found", "largest", largestState.L2BlockHash, "participant", participantState.L2BlockHash)
+
+		if largestStateLower.Header().Number.Uint64() != participantState.L2BlockNumber {
+			return LargestLosesParticipantWins
+		}
+
+		// Perform bisection to find a point of disagreement
+		return PerformBisection(neonGenesisBlock, largest, participant, participantState, ctx, config)
+	}
+}
+
+// linkedBlockHash returns the hash of the block chain's prover serves at
+// number, once chain has linked it to its trusted header.
+func linkedBlockHash(ctx context.Context, chain *HeaderChain, number uint64) (common.Hash, error) {
+	block, err := chain.prover.GetBlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := block.Header().Hash()
+	if err := chain.ExtendForward(ctx, number, hash); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+// PerformBisection searches for the first block the provers disagree on. The
+// block each prover serves at a bisection point must descend from the
+// confirmed anchor neonGenesisBlock through ParentHash, so provers cannot
+// steer the search with hashes that are not part of any chain.
+func PerformBisection(neonGenesisBlock types.Header, largest *ArbitrumClient, participant *ArbitrumClient, participantState MessageTrackingL2Data, ctx context.Context, config *Config) ChallengeResult {
+	left := neonGenesisBlock.Number.Uint64()
+	right := participantState.L2BlockNumber
+
+	// The walks end at hashes the provers claim, so nothing they verify is
+	// stored
+	largestChain := NewHeaderChain(largest, &neonGenesisBlock, defaultHeaderBatchSize, nil)
+	participantChain := NewHeaderChain(participant, &neonGenesisBlock, defaultHeaderBatchSize, nil)
+
+	for left < right-1 {
+		mid := (left + right) / 2
+		largestHash, largestErr := linkedBlockHash(ctx, largestChain, mid)
+		if largestErr != nil {
+			log.Warn("largest prover's block does not descend from the anchor", "prover", largest.URL(), "block", mid, "err", largestErr)
+		}
+		participantHash, participantErr := linkedBlockHash(ctx, participantChain, mid)
+		if participantErr != nil {
+			log.Warn("participant prover's block does not descend from the anchor", "prover", participant.URL(), "block", mid, "err", participantErr)
+		}
+		switch {
+		case largestErr != nil && participantErr != nil:
+			return BothLose
+		case largestErr != nil:
+			return LargestLosesParticipantWins
+		case participantErr != nil:
+			return LargestWinsParticipantLoses
+		}
+
+		if largestHash == participantHash {
+			left = mid
+		} else {
+			right = mid
+		}
+	}
+
+	// Now test the disagreement point
+	log.Debug("testing disagreement", "block", right)
+
+	largestResult, err := VerifyOracles(ctx, largest, right, config)
+	if err != nil {
+		log.Warn("oracle verification failed", "prover", largest.URL(), "block", right, "err", err)
+	}
+	participantResult, err := VerifyOracles(ctx, participant, right, config)
+	if err != nil {
+		log.Warn("oracle verification failed", "prover", participant.URL(), "block", right, "err", err)
+	}
+
+	if largestResult && !participantResult {
+		return LargestWinsParticipantLoses
+	} else if !largestResult && participantResult {
+		return LargestLosesParticipantWins
+	} else if !largestResult && !participantResult {
+		return BothLose
+	} else {
+		// Both win, which shouldn't happen with a disagreement
+		return BothWin
+	}
+}
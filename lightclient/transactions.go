@@ -0,0 +1,106 @@
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var ErrTransactionsRootMismatch = errors.New("transactions do not match the header transactions root")
+var ErrTransactionNotInBlock = errors.New("transaction is not in the block")
+
+// VerifiedTransaction is a transaction together with its position in the
+// block and its proof against the transactions root of a verified header.
+type VerifiedTransaction struct {
+	Transaction *types.Transaction
+	BlockHash   common.Hash
+	BlockNumber uint64
+	Index       uint64
+	Proof       *InclusionProof
+}
+
+// VerifiedBlockTransactions returns all transactions of the block with the
+// trusted hash blockHash, checked against its transactions root.
+func (c *ArbitrumClient) VerifiedBlockTransactions(ctx context.Context, blockHash common.Hash) ([]*VerifiedTransaction, error) {
+	header, err := c.verifiedHeader(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return c.transactionsForHeader(ctx, header)
+}
+
+func (c *ArbitrumClient) transactionsForHeader(ctx context.Context, header *types.Header) ([]*VerifiedTransaction, error) {
+	blockHash := header.Hash()
+	block, err := c.GetBlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block.Hash() != blockHash {
+		return nil, fmt.Errorf("%w: block %s: body served for another header", ErrTransactionsRootMismatch, blockHash.Hex())
+	}
+
+	txs := block.Transactions()
+	txTrie, err := newOrderedTrie(txs)
+	if err != nil {
+		return nil, err
+	}
+	if txTrie.root != header.TxHash {
+		return nil, fmt.Errorf("%w: block %s: computed %s, header %s", ErrTransactionsRootMismatch, blockHash.Hex(), txTrie.root.Hex(), header.TxHash.Hex())
+	}
+
+	verified := make([]*VerifiedTransaction, len(txs))
+	for i, tx := range txs {
+		proof, err := txTrie.prove(uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		verified[i] = &VerifiedTransaction{
+			Transaction: tx,
+			BlockHash:   blockHash,
+			BlockNumber: header.Number.Uint64(),
+			Index:       uint64(i),
+			Proof:       proof,
+		}
+	}
+	return verified, nil
+}
+
+// VerifiedTransactionByHash looks up the block including txHash and returns
+// the transaction proven against that block's transactions root. The prover
+// only names the block: its hash must be the one chain verified at that
+// height, so blocks outside the verified range are rejected.
+func (c *ArbitrumClient) VerifiedTransactionByHash(ctx context.Context, chain *HeaderChain, txHash common.Hash) (*VerifiedTransaction, error) {
+	var location struct {
+		BlockHash   *common.Hash    `json:"blockHash"`
+		BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+	}
+	if err := c.rpcClient.CallContext(ctx, &location, "eth_getTransactionByHash", txHash); err != nil {
+		return nil, err
+	}
+	if location.BlockHash == nil || location.BlockNumber == nil {
+		return nil, fmt.Errorf("%w: %s is pending or unknown", ErrTransactionNotInBlock, txHash.Hex())
+	}
+	number := uint64(*location.BlockNumber)
+	trusted, err := chain.Hash(number)
+	if err != nil {
+		return nil, err
+	}
+	if trusted != *location.BlockHash {
+		return nil, fmt.Errorf("%w: prover places %s in block %s, verified block %d is %s", ErrHeaderLink, txHash.Hex(), location.BlockHash.Hex(), number, trusted.Hex())
+	}
+
+	txs, err := c.VerifiedBlockTransactions(ctx, trusted)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		if tx.Transaction.Hash() == txHash {
+			return tx, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s not in block %s", ErrTransactionNotInBlock, txHash.Hex(), location.BlockHash.Hex())
+}
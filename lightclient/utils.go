@@ -1,4 +1,4 @@
-package main
+package lightclient
 
 import (
 	"bytes"
@@ -83,17 +83,14 @@ func ParseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 			if dapReader != nil && dapReader.IsValidHeaderByte(payload[0]) {
 				payload, err = dapReader.RecoverPayloadFromBatch(ctx, batchNum, batchBlockHash, data, nil, keysetValidationMode != daprovider.KeysetDontValidate)
 				if err != nil {
-					// Matches the way keyset validation was done inside DAS readers i.e logging the error
-					//  But other daproviders might just want to return the error
-					if errors.Is(err, daprovider.ErrSeqMsgValidation) && daprovider.IsDASMessageHeaderByte(payload[0]) {
-						logLevel := log.Error
-						if keysetValidationMode == daprovider.KeysetPanicIfInvalid {
-							logLevel = log.Crit
-						}
-						logLevel(err.Error())
-					} else {
-						return nil, err
+					// An invalid DAS certificate makes the batch empty, as it does
+					// on a node, so the state transition matches honest provers.
+					// Failing to fetch the data is an error though.
+					if errors.Is(err, daprovider.ErrSeqMsgValidation) && daprovider.IsDASMessageHeaderByte(data[40]) {
+						log.Error("Invalid DAS certificate, treating batch as empty", "batch", batchNum, "err", err)
+						return parsedMsg, nil
 					}
+					return nil, err
 				}
 				if payload == nil {
 					return parsedMsg, nil
@@ -105,7 +102,7 @@ func ParseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 
 		if !foundDA {
 			if daprovider.IsDASMessageHeaderByte(payload[0]) {
-				log.Error("No DAS Reader configured, but sequencer message found with DAS header")
+				return nil, ErrNoDASReader
 			} else if daprovider.IsBlobHashesHeaderByte(payload[0]) {
 				return nil, daprovider.ErrNoBlobReader
 			}
@@ -281,10 +278,10 @@ func getMessage(parsedSequencerMsg *sequencerMessage, index int, backend *Multip
 		}
 		return delayed, nil
 	} else if kind == arbstate.BatchSegmentKindAdvanceTimestamp || kind == arbstate.BatchSegmentKindAdvanceL1BlockNumber {
-		fmt.Println("kind", kind)
+		log.Debug("Skipping header advance segment", "kind", kind)
 		return nil, nil
 	} else {
-		fmt.Println("kind", kind)
+		log.Warn("Skipping unknown batch segment kind", "kind", kind)
 		return nil, nil
 	}
 }
@@ -419,6 +416,9 @@ func getAfterDelayedBySeqNum(seqNum int64, seqFilter *bridgegen.SequencerInboxFi
 		}
 
 	}
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
 
 	if afterBatchDelayedCount == 0 {
 		return 0, ErrBatchNotFound
@@ -427,10 +427,10 @@ func getAfterDelayedBySeqNum(seqNum int64, seqFilter *bridgegen.SequencerInboxFi
 	return afterBatchDelayedCount, nil
 }
 
-func setDelayedToBackendByIndexRange(ctx context.Context, client *ethclient.Client, inboxAddress common.Address, bridgeAddress common.Address, fromIndex int64, toIndex int64, backend *MultiplexerBackend) error {
+func setDelayedToBackendByIndexRange(ctx context.Context, client *ethclient.Client, inboxAddress common.Address, bridgeAddress common.Address, fromIndex int64, toIndex int64, afterDelayedAcc common.Hash, backend *MultiplexerBackend) error {
 	// If no delayed messages, the fromIndex - 1 = toIndex
 	if fromIndex-1 == toIndex {
-		fmt.Println("No new delayed msg in current batch")
+		log.Debug("No new delayed msg in current batch")
 		return nil
 	}
 	parsedIBridgeABI, err := bridgegen.IBridgeMetaData.GetAbi()
@@ -449,6 +449,9 @@ func setDelayedToBackendByIndexRange(ctx context.Context, client *ethclient.Clie
 		Topics:    [][]common.Hash{{messageDeliveredID}, {common.BigToHash(big.NewInt(fromIndex)), common.BigToHash(big.NewInt(toIndex))}},
 	}
 	logs, err := client.FilterLogs(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to get MessageDelivered logs: %w", err)
+	}
 
 	var fromBlock uint64
 	var toBlock uint64
@@ -463,11 +466,21 @@ func setDelayedToBackendByIndexRange(ctx context.Context, client *ethclient.Clie
 	}
 
 	delayedBridge, err := arbnode.NewDelayedBridge(client, bridgeAddress, fromBlock-1)
+	if err != nil {
+		return err
+	}
 
 	delayedMsg, err := delayedBridge.LookupMessagesInRange(ctx, big.NewInt(int64(fromBlock)), big.NewInt(int64(toBlock)), nil)
 	if err != nil {
 		return err
 	}
+	bridge, err := bridgegen.NewIBridgeCaller(bridgeAddress, client)
+	if err != nil {
+		return err
+	}
+	if err := verifyDelayedMessages(ctx, bridge, delayedMsg, uint64(fromIndex), uint64(toIndex), afterDelayedAcc); err != nil {
+		return err
+	}
 	for _, msg := range delayedMsg {
 		pos, err := msg.Message.Header.SeqNum()
 		if err != nil {
@@ -0,0 +1,297 @@
+package lightclient
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+)
+
+const testChainId = 412346
+
+func testL2Message(timestamp uint64, blockNumber uint64, l2msg []byte) *arbostypes.L1IncomingMessage {
+	return &arbostypes.L1IncomingMessage{
+		Header: &arbostypes.L1IncomingMessageHeader{
+			Kind:        arbostypes.L1MessageType_L2Message,
+			Poster:      l1pricing.BatchPosterAddress,
+			BlockNumber: blockNumber,
+			Timestamp:   timestamp,
+			RequestId:   nil,
+			L1BaseFee:   big.NewInt(0),
+		},
+		L2msg: l2msg,
+	}
+}
+
+// testDelayedMessage is a delayed message carrying a single transaction, so
+// LoadMessages accepts it.
+func testDelayedMessage(t *testing.T, seqNum uint64) *arbostypes.L1IncomingMessage {
+	t.Helper()
+	tx := types.NewTransaction(seqNum, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestId := common.BigToHash(new(big.Int).SetUint64(seqNum))
+	return &arbostypes.L1IncomingMessage{
+		Header: &arbostypes.L1IncomingMessageHeader{
+			Kind:        arbostypes.L1MessageType_L2Message,
+			Poster:      common.Address{2},
+			BlockNumber: 7,
+			Timestamp:   70,
+			RequestId:   &requestId,
+			L1BaseFee:   big.NewInt(3),
+		},
+		L2msg: append([]byte{arbos.L2MessageKind_SignedTx}, encoded...),
+	}
+}
+
+func parseBatch(t *testing.T, batch []byte) *sequencerMessage {
+	t.Helper()
+	parsed, err := ParseSequencerMessage(context.Background(), 1, common.Hash{}, batch, nil, daprovider.KeysetDontValidate)
+	if err != nil {
+		t.Fatalf("ParseSequencerMessage: %v", err)
+	}
+	return parsed
+}
+
+func loadBatch(t *testing.T, parsed *sequencerMessage, delayedStart uint64, delayed map[uint64]*arbostypes.L1IncomingMessage) []*arbostypes.L1IncomingMessage {
+	t.Helper()
+	backend := &MultiplexerBackend{delayedMessages: delayed}
+	messages, err := LoadMessages(parsed, delayedStart, backend, testChainId)
+	if err != nil {
+		t.Fatalf("LoadMessages: %v", err)
+	}
+	return messages
+}
+
+func checkMessages(t *testing.T, got []*arbostypes.L1IncomingMessage, want []*arbostypes.L1IncomingMessage) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Header.Kind != w.Header.Kind {
+			t.Errorf("message %d: kind %d, want %d", i, g.Header.Kind, w.Header.Kind)
+		}
+		if g.Header.Poster != w.Header.Poster {
+			t.Errorf("message %d: poster %s, want %s", i, g.Header.Poster, w.Header.Poster)
+		}
+		if g.Header.BlockNumber != w.Header.BlockNumber {
+			t.Errorf("message %d: block number %d, want %d", i, g.Header.BlockNumber, w.Header.BlockNumber)
+		}
+		if g.Header.Timestamp != w.Header.Timestamp {
+			t.Errorf("message %d: timestamp %d, want %d", i, g.Header.Timestamp, w.Header.Timestamp)
+		}
+		if (g.Header.RequestId == nil) != (w.Header.RequestId == nil) || (w.Header.RequestId != nil && *g.Header.RequestId != *w.Header.RequestId) {
+			t.Errorf("message %d: request id %v, want %v", i, g.Header.RequestId, w.Header.RequestId)
+		}
+		if (g.Header.L1BaseFee == nil) != (w.Header.L1BaseFee == nil) || (w.Header.L1BaseFee != nil && g.Header.L1BaseFee.Cmp(w.Header.L1BaseFee) != 0) {
+			t.Errorf("message %d: L1 base fee %v, want %v", i, g.Header.L1BaseFee, w.Header.L1BaseFee)
+		}
+		if !bytes.Equal(g.L2msg, w.L2msg) {
+			t.Errorf("message %d: L2 message %x, want %x", i, g.L2msg, w.L2msg)
+		}
+		if (g.BatchGasCost == nil) != (w.BatchGasCost == nil) {
+			t.Errorf("message %d: batch gas cost %v, want %v", i, g.BatchGasCost, w.BatchGasCost)
+		}
+	}
+}
+
+func TestParseSequencerMessageHeader(t *testing.T) {
+	builder := NewBatchBuilder().AddL2Message([]byte{1})
+	builder.MinTimestamp = 100
+	builder.MaxTimestamp = 200
+	builder.MinL1Block = 10
+	builder.MaxL1Block = 20
+	builder.AfterDelayedMessages = 5
+	batch, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := parseBatch(t, batch)
+	if parsed.minTimestamp != 100 || parsed.maxTimestamp != 200 {
+		t.Errorf("timestamps [%d, %d], want [100, 200]", parsed.minTimestamp, parsed.maxTimestamp)
+	}
+	if parsed.minL1Block != 10 || parsed.maxL1Block != 20 {
+		t.Errorf("L1 blocks [%d, %d], want [10, 20]", parsed.minL1Block, parsed.maxL1Block)
+	}
+	if parsed.afterDelayedMessages != 5 {
+		t.Errorf("after delayed messages %d, want 5", parsed.afterDelayedMessages)
+	}
+}
+
+func TestLoadMessagesSegmentKinds(t *testing.T) {
+	first := []byte{0x04, 0xaa, 0xbb}
+	second := bytes.Repeat([]byte{0xcc}, 64)
+	delayed := testDelayedMessage(t, 5)
+
+	tests := []struct {
+		name  string
+		build func(*BatchBuilder)
+		want  []*arbostypes.L1IncomingMessage
+	}{
+		{
+			name:  "l2 message",
+			build: func(b *BatchBuilder) { b.AddL2Message(first) },
+			want:  []*arbostypes.L1IncomingMessage{testL2Message(0, 0, first)},
+		},
+		{
+			name:  "brotli l2 message",
+			build: func(b *BatchBuilder) { b.AddL2MessageBrotli(second) },
+			want:  []*arbostypes.L1IncomingMessage{testL2Message(0, 0, second)},
+		},
+		{
+			name:  "delayed message",
+			build: func(b *BatchBuilder) { b.AddDelayedMessage() },
+			want:  []*arbostypes.L1IncomingMessage{delayed},
+		},
+		{
+			name:  "advance timestamp",
+			build: func(b *BatchBuilder) { b.AdvanceTimestamp(10).AddL2Message(first) },
+			want:  []*arbostypes.L1IncomingMessage{testL2Message(10, 0, first)},
+		},
+		{
+			name:  "advance L1 block number",
+			build: func(b *BatchBuilder) { b.AdvanceL1BlockNumber(3).AddL2Message(first) },
+			want:  []*arbostypes.L1IncomingMessage{testL2Message(0, 3, first)},
+		},
+		{
+			name: "all kinds",
+			build: func(b *BatchBuilder) {
+				b.AdvanceTimestamp(10).
+					AddL2Message(first).
+					AdvanceL1BlockNumber(2).
+					AddDelayedMessage().
+					AdvanceTimestamp(5).
+					AddL2MessageBrotli(second)
+			},
+			want: []*arbostypes.L1IncomingMessage{
+				testL2Message(10, 0, first),
+				delayed,
+				testL2Message(15, 2, second),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewBatchBuilder()
+			tt.build(builder)
+			batch, err := builder.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages := loadBatch(t, parseBatch(t, batch), 5, map[uint64]*arbostypes.L1IncomingMessage{5: delayed})
+			checkMessages(t, messages, tt.want)
+		})
+	}
+}
+
+func TestParseSequencerMessageMalformedRLP(t *testing.T) {
+	msg := []byte{0x04, 0x01}
+	stream, err := NewBatchBuilder().AddL2Message(msg).Segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A list where a segment string is expected ends the segment stream
+	stream = append(stream, 0xc2, 0x01, 0x02)
+	compressed, err := arbcompress.CompressWell(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := NewBatchBuilder().BuildRaw(append([]byte{daprovider.BrotliMessageHeaderByte}, compressed...))
+
+	parsed := parseBatch(t, batch)
+	if len(parsed.segments) != 1 {
+		t.Fatalf("got %d segments, want the 1 before the malformed one", len(parsed.segments))
+	}
+	checkMessages(t, loadBatch(t, parsed, 0, nil), []*arbostypes.L1IncomingMessage{testL2Message(0, 0, msg)})
+}
+
+func TestLoadMessagesUnknownSegmentKind(t *testing.T) {
+	batch, err := NewBatchBuilder().AddL2Message([]byte{0x04}).AddSegment(0x7f, []byte{1, 2, 3}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := parseBatch(t, batch)
+	if len(parsed.segments) != 2 {
+		t.Fatalf("got %d segments, want 2", len(parsed.segments))
+	}
+	// A segment of unknown kind drops the whole batch
+	if messages := loadBatch(t, parsed, 0, nil); messages != nil {
+		t.Fatalf("got %d messages, want none", len(messages))
+	}
+}
+
+func TestParseSequencerMessageTruncatedHeader(t *testing.T) {
+	_, err := ParseSequencerMessage(context.Background(), 1, common.Hash{}, make([]byte, 39), nil, daprovider.KeysetDontValidate)
+	if err == nil {
+		t.Fatal("expected an error for a 39 byte batch")
+	}
+}
+
+func TestParseSequencerMessageZeroheavy(t *testing.T) {
+	builder := NewBatchBuilder().AdvanceTimestamp(3).AddL2Message([]byte{0x04, 0x00, 0x00, 0x01}).AddL2MessageBrotli(make([]byte, 32))
+	plain, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	zeroheavy, err := builder.BuildZeroheavy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(plain, zeroheavy) {
+		t.Fatal("zeroheavy batch is not encoded")
+	}
+
+	want := loadBatch(t, parseBatch(t, plain), 0, nil)
+	checkMessages(t, loadBatch(t, parseBatch(t, zeroheavy), 0, nil), want)
+	checkMessages(t, want, []*arbostypes.L1IncomingMessage{
+		testL2Message(3, 0, []byte{0x04, 0x00, 0x00, 0x01}),
+		testL2Message(3, 0, make([]byte, 32)),
+	})
+}
+
+func TestParseSequencerMessageEmptyBrotli(t *testing.T) {
+	batch, err := NewBatchBuilder().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := parseBatch(t, batch)
+	if len(parsed.segments) != 0 {
+		t.Fatalf("got %d segments, want none", len(parsed.segments))
+	}
+	if messages := loadBatch(t, parsed, 0, nil); len(messages) != 0 {
+		t.Fatalf("got %d messages, want none", len(messages))
+	}
+}
+
+func TestParseSequencerMessageSegmentLimit(t *testing.T) {
+	builder := NewBatchBuilder()
+	for i := 0; i <= arbstate.MaxSegmentsPerSequencerMessage; i++ {
+		builder.AdvanceTimestamp(0)
+	}
+	batch, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := parseBatch(t, batch)
+	if len(parsed.segments) != arbstate.MaxSegmentsPerSequencerMessage {
+		t.Fatalf("got %d segments, want the limit of %d", len(parsed.segments), arbstate.MaxSegmentsPerSequencerMessage)
+	}
+}